@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/liblxn/lxnc/locale"
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// cacheFormat is the version of the cache entries. It has to be increased when
+// the layout of the entries changes.
+const cacheFormat = "1"
+
+// buildCache is a content-addressed cache for the parsed messages of translation
+// files and for the compiled output files. Each entry is stored in a file whose
+// name is the hash of the entry's key. The keys contain the version of lxnc and
+// of the CLDR data, so a new release never reuses the entries of an old one.
+// If the directory is empty, the entries are only kept in memory, e.g. while
+// watching the inputs. The memory only holds the entries which were used by the
+// latest build, so it does not grow with each change of the inputs.
+//
+// The messages of a translation file depend on the files it includes, which are
+// only known after parsing. So an entry of parsed messages holds the hashes of
+// the included files and is only reused if all of them are unchanged.
+type buildCache struct {
+	dir    string
+	inputs []fileHash // all files the output depends on
+
+	mtx          sync.Mutex
+	mem          map[string][]byte   // entries if there is no directory
+	used         map[string]struct{} // keys of the entries in mem which were used since the last reset
+	fileHits     int
+	fileMisses   int
+	outputHits   int
+	outputMisses int
+}
+
+// fileHash is the content hash of a file, which a cache entry depends on.
+type fileHash struct {
+	Name string
+	Hash string
+}
+
+type messagesEntry struct {
+	Deps     []fileHash // included files
+	Messages []byte     // encoded with lxn.EncodeParsedMessages
+}
+
+type outputEntry struct {
+	Output []byte
+}
+
+// parsedFile is the result of parsing a translation file with the cache.
+type parsedFile struct {
+	msgs []lxn.Message
+	deps []fileHash // the file itself and all included files
+	err  error
+}
+
+func newBuildCache(dir string) (*buildCache, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %v", err)
+	}
+	return &buildCache{dir: dir}, nil
+}
+
+// key returns the hash of the given key parts together with the versions.
+func (c *buildCache) key(parts ...string) string {
+	h := sha256.New()
+	for _, part := range append([]string{cacheFormat, lxncVersion(), locale.CLDRVersion}, parts...) {
+		fmt.Fprintf(h, "%d:%s\x00", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *buildCache) get(key string, entry any) bool {
+	data, ok := c.read(key)
+	if !ok {
+		return false
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(entry) == nil
+}
+
+func (c *buildCache) read(key string) ([]byte, bool) {
+	if c.dir == "" {
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		data, has := c.mem[key]
+		if has {
+			c.markUsed(key)
+		}
+		return data, has
+	}
+
+	data, err := os.ReadFile(c.path(key))
+	return data, err == nil
+}
+
+// put stores the entry. Errors are ignored, since the entry will be produced
+// again by the next run. The entry is written to a temporary file first, so
+// concurrent runs never see a partial entry.
+func (c *buildCache) put(key string, entry any) {
+	var buf bytes.Buffer
+	if gob.NewEncoder(&buf).Encode(entry) != nil {
+		return
+	}
+	if c.dir == "" {
+		c.mtx.Lock()
+		if c.mem == nil {
+			c.mem = make(map[string][]byte)
+		}
+		c.mem[key] = buf.Bytes()
+		c.markUsed(key)
+		c.mtx.Unlock()
+		return
+	}
+
+	path := c.path(key)
+	if os.MkdirAll(filepath.Dir(path), 0777) != nil {
+		return
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// markUsed records that the entry in mem was used. The mutex has to be held.
+func (c *buildCache) markUsed(key string) {
+	if c.used == nil {
+		c.used = make(map[string]struct{})
+	}
+	c.used[key] = struct{}{}
+}
+
+func (c *buildCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// parseMessages parses the translation files in parallel, reusing the cached
+// messages of unchanged files. The errors of all files are collected into one
+// error list. The parsed files are added to the inputs of the output, even if
+// they failed.
+func (c *buildCache) parseMessages(filenames []string) ([]lxn.Message, error) {
+	files := make([]parsedFile, len(filenames))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	for i, filename := range filenames {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, filename string) {
+			defer func() { <-sem; wg.Done() }()
+			files[i] = c.parseFile(filename)
+		}(i, filename)
+	}
+	wg.Wait()
+
+	// A file which is included by another input is skipped, since its
+	// messages are already part of the including file.
+	included := make([][]string, len(files))
+	for i, f := range files {
+		for _, dep := range f.deps[1:] { // the first one is the file itself
+			included[i] = append(included[i], dep.Name)
+		}
+	}
+	skipped := lxn.IncludedFiles(filenames, included)
+
+	var (
+		messages []lxn.Message
+		errs     lxn.ErrorList
+	)
+	for i, f := range files {
+		if skipped[i] {
+			continue
+		}
+		// The files of a failed file are recorded as well, so fixing one of them
+		// is noticed while watching.
+		c.inputs = append(c.inputs, f.deps...)
+		switch err := f.err.(type) {
+		case nil:
+			messages = append(messages, f.msgs...)
+		case lxn.ErrorList:
+			errs = append(errs, err...)
+		default:
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	return messages, nil
+}
+
+func (c *buildCache) parseFile(filename string) parsedFile {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return parsedFile{deps: []fileHash{{Name: filename}}, err: err}
+	}
+	self := fileHash{Name: filename, Hash: hashBytes(src)}
+	key := c.key("messages", self.Name, self.Hash)
+
+	var entry messagesEntry
+	if c.get(key, &entry) && depsUnchanged(entry.Deps) {
+		msgs, err := lxn.DecodeParsedMessages(bytes.NewReader(entry.Messages))
+		if err == nil {
+			c.count(&c.fileHits)
+			return parsedFile{msgs: msgs, deps: append([]fileHash{self}, entry.Deps...)}
+		}
+	}
+	c.count(&c.fileMisses)
+
+	// All included files are recorded, so the entry can be checked later. A
+	// missing file is recorded without a hash, so its creation is noticed while
+	// watching.
+	var deps []fileHash
+	readFile := func(filename string) ([]byte, error) {
+		content, err := os.ReadFile(filename)
+		dep := fileHash{Name: filename}
+		if err == nil {
+			dep.Hash = hashBytes(content)
+		}
+		deps = append(deps, dep)
+		return content, err
+	}
+	msgs, err := lxn.ParseSource(filename, src, readFile)
+	if err != nil {
+		return parsedFile{deps: append([]fileHash{self}, deps...), err: err}
+	}
+
+	var buf bytes.Buffer
+	if lxn.EncodeParsedMessages(&buf, msgs) == nil {
+		c.put(key, messagesEntry{Deps: deps, Messages: buf.Bytes()})
+	}
+	return parsedFile{msgs: msgs, deps: append([]fileHash{self}, deps...)}
+}
+
+// addInput adds a file to the inputs of the output.
+func (c *buildCache) addInput(filename string, content []byte) {
+	c.inputs = append(c.inputs, fileHash{Name: filename, Hash: hashBytes(content)})
+}
+
+// outputKey returns the key of the output, which depends on all inputs and on
+// the options which affect the output. The inputs include the files of the
+// fallback locales, since they are parsed with parseMessages as well.
+func (c *buildCache) outputKey(opts options) string {
+	parts := []string{
+		"output",
+		string(opts.command),
+		opts.locale,
+		opts.fallbackDir,
+		fmt.Sprint(opts.catalog, opts.bidiIsolate, opts.withNotes),
+		opts.withNames,
+		fmt.Sprint(opts.onConflict, opts.prefer, opts.namespace),
+	}
+	for _, input := range c.inputs {
+		parts = append(parts, input.Name, input.Hash)
+	}
+	return c.key(parts...)
+}
+
+// output returns the cached output for the key.
+func (c *buildCache) output(key string) ([]byte, bool) {
+	var entry outputEntry
+	if c.get(key, &entry) {
+		c.count(&c.outputHits)
+		return entry.Output, true
+	}
+	c.count(&c.outputMisses)
+	return nil, false
+}
+
+func (c *buildCache) putOutput(key string, output []byte) {
+	c.put(key, outputEntry{Output: output})
+}
+
+// reset prepares the cache for the next build. It clears the inputs of the
+// output and the statistics. The entries are kept, except for the entries in
+// memory which the previous build did not use.
+func (c *buildCache) reset() {
+	c.mtx.Lock()
+	for key := range c.mem {
+		if _, has := c.used[key]; !has {
+			delete(c.mem, key)
+		}
+	}
+	clear(c.used)
+	c.mtx.Unlock()
+
+	c.inputs = c.inputs[:0]
+	c.fileHits, c.fileMisses = 0, 0
+	c.outputHits, c.outputMisses = 0, 0
+}
+
+func (c *buildCache) count(n *int) {
+	c.mtx.Lock()
+	*n++
+	c.mtx.Unlock()
+}
+
+func (c *buildCache) printStats() {
+	fmt.Fprintf(os.Stderr, "cache: %d file hits, %d file misses, %d output hits, %d output misses\n",
+		c.fileHits, c.fileMisses, c.outputHits, c.outputMisses)
+}
+
+func depsUnchanged(deps []fileHash) bool {
+	for _, dep := range deps {
+		content, err := os.ReadFile(dep.Name)
+		if err != nil || hashBytes(content) != dep.Hash {
+			return false
+		}
+	}
+	return true
+}
+
+func hashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// lxncVersion returns the version of lxnc. If the build contains the VCS
+// revision, it is added, so the cache entries of different revisions are not
+// mixed up. Builds from modified sources add the hash of the executable. The
+// version is determined once, since it is part of every cache key.
+var lxncVersion = sync.OnceValue(func() string {
+	v := version
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision":
+			v += "+" + s.Value
+		case s.Key == "vcs.modified" && s.Value == "true":
+			if exe, err := os.Executable(); err == nil {
+				if content, err := os.ReadFile(exe); err == nil {
+					v += "+" + hashBytes(content)
+				}
+			}
+		}
+	}
+	return v
+})
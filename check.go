@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// check parses and validates the given translation files without producing any
+// output. All errors of a file are reported in one pass, and the files are
+// checked independently of each other, so an error in one file does not hide
+// the errors of the others. Message references are resolved across all files.
+func check(opts options) {
+	if len(opts.inputFiles) == 0 {
+		fatalf("missing translation files")
+	}
+
+	type checkedFile struct {
+		msgs []lxn.Message
+		err  error
+	}
+
+	// A file which is included by another input is checked as part of the
+	// including file only.
+	files := make([]checkedFile, len(opts.inputFiles))
+	included := make(map[string]struct{})
+	readFile := func(filename string) ([]byte, error) {
+		included[filepath.Clean(filename)] = struct{}{}
+		return os.ReadFile(filename)
+	}
+	for i, inputFile := range opts.inputFiles {
+		src, err := os.ReadFile(inputFile)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		files[i].msgs, files[i].err = lxn.ParseSource(inputFile, src, readFile)
+	}
+
+	failed := false
+	var messages []lxn.Message
+	for i, f := range files {
+		if _, has := included[filepath.Clean(opts.inputFiles[i])]; has {
+			continue
+		}
+		if f.err != nil {
+			printErr(f.err)
+			failed = true
+			continue
+		}
+		messages = append(messages, f.msgs...)
+	}
+	if failed {
+		os.Exit(1)
+	}
+
+	if err := lxn.ResolveReferences(messages); err != nil {
+		fatalErr(err)
+	}
+	lxn.ValidateMessages(messages, warner{})
+}
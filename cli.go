@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -13,41 +14,191 @@ type command string
 const (
 	compileCommand command = "compile"
 	bundleCommand  command = "bundle"
+	linkCommand    command = "link"
+	exportCommand  command = "export"
+	checkCommand   command = "check"
+	formatCommand  command = "fmt"
+	lspCommand     command = "lsp"
 )
 
 type options struct {
-	command    command
-	catalog    bool
-	locale     string
-	outputFile string
-	inputFiles []string
+	command     command
+	catalog     bool
+	locale      string
+	fallbackDir string
+	withNames   string
+	bidiIsolate bool
+	withNotes   bool
+	sourceFiles string
+	onConflict  string
+	prefer      string
+	namespace   bool
+	include     string
+	exclude     string
+	outputFile  string
+	write       bool
+	cacheDir    string
+	cacheStats  bool
+	watch       bool
+	diff        bool
+	inputFiles  []string
 }
 
 func printUsage(w io.Writer) {
 	fmt.Fprintln(w, `USAGE`)
-	fmt.Fprintln(w, `  lxnc compile <locale> [<options>] <translation file> ...`)
+	fmt.Fprintln(w, `  lxnc compile <locale> [<options>] <translation file|directory|pattern> ...`)
 	fmt.Fprintln(w, `  lxnc bundle [<options>] <catalog file> ...`)
+	fmt.Fprintln(w, `  lxnc link [<options>] <catalog|dictionary file> ...`)
+	fmt.Fprintln(w, `  lxnc export <locale> [<options>] <translation file|directory|pattern> ...`)
+	fmt.Fprintln(w, `  lxnc check [<options>] <translation file|directory|pattern> ...`)
+	fmt.Fprintln(w, `  lxnc fmt [<options>] <translation file> ...`)
+	fmt.Fprintln(w, `  lxnc lsp`)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, `DESCRIPTION`)
 	fmt.Fprintln(w, `  lxnc converts the given input files into a single binary output file.`)
 	fmt.Fprintln(w, `  The output file is either a dictionary or a catalog. A dictionary contains`)
 	fmt.Fprintln(w, `  all messages of the translation files and the necessary locale data to format`)
 	fmt.Fprintln(w, `  these messages. A catalog, on the other hand, contains only the messages with`)
-	fmt.Fprintln(w, `  a locale id to define the language.`)
+	fmt.Fprintln(w, `  a locale id to define the language. Both start with a header which records`)
+	fmt.Fprintln(w, `  the kind of the file, the schema version, and the versions of lxnc and of the`)
+	fmt.Fprintln(w, `  CLDR data which produced it. Files with a newer schema version are rejected.`)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, `  The 'compile' command compiles translation files into a single binary output`)
 	fmt.Fprintln(w, `  file of the specified locale.`)
 	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  The translation files of the 'compile', 'export' and 'check' commands can also`)
+	fmt.Fprintln(w, `  be given as directories, which are searched recursively for '*.lxn' files, or`)
+	fmt.Fprintln(w, `  as glob patterns, where '**' matches any number of directories (e.g.`)
+	fmt.Fprintln(w, `  'translations/**/de/*.lxn'). The files found are compiled in the order of`)
+	fmt.Fprintln(w, `  their paths. A file which is included by another input file is only compiled`)
+	fmt.Fprintln(w, `  as part of the including file.`)
+	fmt.Fprintln(w)
 	fmt.Fprintln(w, `  The 'bundle' command merges binary catalog files into a single binary output`)
-	fmt.Fprintln(w, `  file. All input files must reference the same locale.`)
+	fmt.Fprintln(w, `  file. All input files must reference the same locale. Messages which are`)
+	fmt.Fprintln(w, `  defined by more than one catalog are resolved with the conflict policy (see`)
+	fmt.Fprintln(w, `  --on-conflict), and every overridden message is listed in a report on stderr.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  The 'link' command converts binary catalog files into a dictionary, or links`)
+	fmt.Fprintln(w, `  existing dictionaries against the CLDR data of this version of lxnc. The`)
+	fmt.Fprintln(w, `  input files must reference the same locale or its parent locales (e.g. 'de'`)
+	fmt.Fprintln(w, `  for 'de-AT'). Messages of a parent locale are only used if the most specific`)
+	fmt.Fprintln(w, `  locale does not define them, and all of these messages are listed in a`)
+	fmt.Fprintln(w, `  report on stderr. Messages of the same locale are merged like in the 'bundle'`)
+	fmt.Fprintln(w, `  command.`)
+	fmt.Fprintln(w, `  The display names of input dictionaries are kept unless --with-names is`)
+	fmt.Fprintln(w, `  given.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  The 'export' command writes the messages of the translation files into an`)
+	fmt.Fprintln(w, `  XLIFF 1.2 file, which can be passed to translators. The translator notes of`)
+	fmt.Fprintln(w, `  the messages are exported as well.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  The 'check' command parses and validates translation files without writing`)
+	fmt.Fprintln(w, `  any output. The parser recovers from syntax errors, so all independent errors`)
+	fmt.Fprintln(w, `  of the files are reported in one pass.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  The 'fmt' command formats translation files into canonical form and writes`)
+	fmt.Fprintln(w, `  them to stdout. It normalizes the indentation, the spacing of section headers`)
+	fmt.Fprintln(w, `  and replacements, the case of option names, and the order of plural options.`)
+	fmt.Fprintln(w, `  Comments are preserved.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  The 'lsp' command starts a language server for translation files, which`)
+	fmt.Fprintln(w, `  speaks the Language Server Protocol over stdin and stdout. The locale of a`)
+	fmt.Fprintln(w, `  file is taken from the 'locale' initialization option or from the name of`)
+	fmt.Fprintln(w, `  the file's directory.`)
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, `OPTIONS`)
+	fmt.Fprintln(w, `  --bidi-isolate`)
+	fmt.Fprintln(w, `      Mark all replacements for bidi isolation if the locale is written from right`)
+	fmt.Fprintln(w, `      to left. Clients have to enclose the replaced values in the isolation`)
+	fmt.Fprintln(w, `      characters FSI (U+2068) and PDI (U+2069), so mixed-direction messages are`)
+	fmt.Fprintln(w, `      rendered correctly. Not valid for catalogs.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --cache=<cache-dir>`)
+	fmt.Fprintln(w, `      Store the parsed messages of each translation file and the generated output`)
+	fmt.Fprintln(w, `      files in the given cache directory. They are reused by later runs as long`)
+	fmt.Fprintln(w, `      as the files, the options, and the versions of lxnc and of the CLDR data`)
+	fmt.Fprintln(w, `      are unchanged. Only valid for the 'compile', 'export', 'bundle' and 'link'`)
+	fmt.Fprintln(w, `      commands.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --cache-stats`)
+	fmt.Fprintln(w, `      Print the number of cache hits and misses. Only valid for the 'compile',`)
+	fmt.Fprintln(w, `      'export', 'bundle' and 'link' commands.`)
+	fmt.Fprintln(w)
 	fmt.Fprintln(w, `  --catalog`)
 	fmt.Fprintln(w, `      Tell the compiler that a catalog should be produces instead of a dictionary.`)
 	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --exclude=<pattern>,...`)
+	fmt.Fprintln(w, `      Skip the translation files in directories and glob patterns which match any`)
+	fmt.Fprintln(w, `      of the given patterns. The patterns are matched against the path relative`)
+	fmt.Fprintln(w, `      to the directory, or against the file name if they contain no slash (e.g.`)
+	fmt.Fprintln(w, `      'draft-*.lxn,testdata/**').`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --fallback=<source-dir>`)
+	fmt.Fprintln(w, `      Compile the translation files of the locale and all of its parent locales.`)
+	fmt.Fprintln(w, `      The source directory contains a subdirectory for each locale (e.g. 'de-AT',`)
+	fmt.Fprintln(w, `      'de', and 'root'). Each message which is missing for the locale is taken`)
+	fmt.Fprintln(w, `      from the nearest parent locale that defines it. All inherited messages are`)
+	fmt.Fprintln(w, `      listed in a report on stderr. Only valid for the 'compile' command.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --include=<pattern>,...`)
+	fmt.Fprintln(w, `      Only use the translation files in directories and glob patterns which match`)
+	fmt.Fprintln(w, `      any of the given patterns. The patterns are matched like the patterns of`)
+	fmt.Fprintln(w, `      --exclude.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --namespace`)
+	fmt.Fprintln(w, `      Put the messages of each input file into a section named after the file`)
+	fmt.Fprintln(w, `      without its extension. The sections of the messages become subsections,`)
+	fmt.Fprintln(w, `      e.g. 'cart' in 'shop.lxnc' becomes 'shop.cart'. Only valid for the 'bundle'`)
+	fmt.Fprintln(w, `      and 'link' commands.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --on-conflict=<policy>`)
+	fmt.Fprintln(w, `      Specify how messages which are defined more than once are resolved:`)
+	fmt.Fprintln(w, `        error                 fail with an error for each conflict`)
+	fmt.Fprintln(w, `        first-wins            keep the message which was defined first`)
+	fmt.Fprintln(w, `        last-wins             keep the message which was defined last (default)`)
+	fmt.Fprintln(w, `        prefer-by-file-order  keep the message of the input file which comes`)
+	fmt.Fprintln(w, `                              first in --prefer`)
+	fmt.Fprintln(w, `      Only valid for the 'bundle' and 'link' commands.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --prefer=<input file>,...`)
+	fmt.Fprintln(w, `      Specify the order of preference for the prefer-by-file-order policy. The`)
+	fmt.Fprintln(w, `      input files which are not listed come last, and conflicts between them`)
+	fmt.Fprintln(w, `      are resolved like first-wins. Only valid for the 'bundle' and 'link'`)
+	fmt.Fprintln(w, `      commands.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --source=<translation file>,...`)
+	fmt.Fprintln(w, `      Validate the markup tags of the messages against the messages of the given`)
+	fmt.Fprintln(w, `      translation files, which are usually the files of the source locale. A`)
+	fmt.Fprintln(w, `      warning is printed for each message whose tags differ from the tags of the`)
+	fmt.Fprintln(w, `      corresponding source message.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --watch`)
+	fmt.Fprintln(w, `      Keep running and rebuild the output file whenever one of the input files,`)
+	fmt.Fprintln(w, `      their included files, or the files in the input directories change. Only`)
+	fmt.Fprintln(w, `      the changed files are parsed again, and the output file is replaced`)
+	fmt.Fprintln(w, `      atomically if its content changed. Only valid for the 'compile', 'export',`)
+	fmt.Fprintln(w, `      'bundle' and 'link' commands.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --with-names=<locale>,...`)
+	fmt.Fprintln(w, `      Embed the display names of the given locales (e.g. 'de,en-GB') into the`)
+	fmt.Fprintln(w, `      dictionary. The names are localized for the locale of the dictionary, which`)
+	fmt.Fprintln(w, `      allows to render a language picker. Not valid for catalogs.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  --with-notes`)
+	fmt.Fprintln(w, `      Keep the translator notes (doc comments starting with '///') of the messages`)
+	fmt.Fprintln(w, `      in the catalog. Only valid for catalogs.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  -d, --diff`)
+	fmt.Fprintln(w, `      Print a diff between the translation files and their formatted version`)
+	fmt.Fprintln(w, `      instead of the formatted files. Only valid for the 'fmt' command.`)
+	fmt.Fprintln(w)
 	fmt.Fprintln(w, `  -o <output-file>, --out=<output-file>`)
 	fmt.Fprintln(w, `      Specify the output file of the generated dictionary or catalog files.`)
-	fmt.Fprintln(w, `      Defaults to '<locale>.lxnc'.`)
+	fmt.Fprintln(w, `      Defaults to '<locale>.lxnc', or '<locale>.xlf' for the 'export' command.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `  -w, --write`)
+	fmt.Fprintln(w, `      Write the formatted version back to the translation files instead of`)
+	fmt.Fprintln(w, `      printing it. Only valid for the 'fmt' command.`)
 	fmt.Fprintln(w)
 }
 
@@ -69,15 +220,32 @@ func parseCommandLine() options {
 		command: command(nextArg("missing command")),
 	}
 
-	if opts.command == compileCommand {
+	if opts.command == compileCommand || opts.command == exportCommand {
 		opts.locale = nextArg("missing locale")
 	}
 
 	fset := flag.NewFlagSet("lxnc", flag.ContinueOnError)
 	fset.Usage = func() {}
+	fset.BoolVar(&opts.bidiIsolate, "bidi-isolate", false, "")
 	fset.BoolVar(&opts.catalog, "catalog", false, "")
+	fset.StringVar(&opts.fallbackDir, "fallback", "", "")
+	fset.StringVar(&opts.withNames, "with-names", "", "")
+	fset.BoolVar(&opts.withNotes, "with-notes", false, "")
+	fset.StringVar(&opts.sourceFiles, "source", "", "")
+	fset.StringVar(&opts.onConflict, "on-conflict", "last-wins", "")
+	fset.StringVar(&opts.prefer, "prefer", "", "")
+	fset.BoolVar(&opts.namespace, "namespace", false, "")
+	fset.StringVar(&opts.include, "include", "", "")
+	fset.StringVar(&opts.exclude, "exclude", "", "")
 	fset.StringVar(&opts.outputFile, "out", "", "")
 	fset.StringVar(&opts.outputFile, "o", "", "")
+	fset.StringVar(&opts.cacheDir, "cache", "", "")
+	fset.BoolVar(&opts.cacheStats, "cache-stats", false, "")
+	fset.BoolVar(&opts.watch, "watch", false, "")
+	fset.BoolVar(&opts.write, "write", false, "")
+	fset.BoolVar(&opts.write, "w", false, "")
+	fset.BoolVar(&opts.diff, "diff", false, "")
+	fset.BoolVar(&opts.diff, "d", false, "")
 
 	switch fset.Parse(args) {
 	case nil:
@@ -90,6 +258,32 @@ func parseCommandLine() options {
 		os.Exit(1)
 	}
 
+	fset.Visit(func(f *flag.Flag) {
+		if commands, has := flagCommands[f.Name]; has && !slices.Contains(commands, opts.command) {
+			fmt.Fprintf(os.Stderr, "flag -%s is not valid for the '%s' command\n", f.Name, opts.command)
+			fmt.Fprintln(os.Stderr)
+			printUsage(os.Stderr)
+			os.Exit(1)
+		}
+	})
+
 	opts.inputFiles = fset.Args()
 	return opts
 }
+
+// flagCommands holds the commands of the flags which are only valid for some of
+// the commands.
+var flagCommands = map[string][]command{
+	"fallback":    {compileCommand},
+	"with-notes":  {compileCommand, bundleCommand},
+	"namespace":   {bundleCommand, linkCommand},
+	"on-conflict": {bundleCommand, linkCommand},
+	"prefer":      {bundleCommand, linkCommand},
+	"cache":       {compileCommand, exportCommand, bundleCommand, linkCommand},
+	"cache-stats": {compileCommand, exportCommand, bundleCommand, linkCommand},
+	"watch":       {compileCommand, exportCommand, bundleCommand, linkCommand},
+	"write":       {formatCommand},
+	"w":           {formatCommand},
+	"diff":        {formatCommand},
+	"d":           {formatCommand},
+}
@@ -234,6 +234,25 @@ func forEachPluralRules(data *cldr.Data, typ pluralRulesType, iter func(data plu
 	}
 }
 
+type pluralRangesData struct {
+	lang   string
+	ranges []cldr.PluralRange
+}
+
+func forEachPluralRanges(data *cldr.Data, iter func(data pluralRangesData)) {
+	langs := languages(data)
+	for _, r := range data.Plurals.Ranges {
+		for _, lang := range r.Locales {
+			if _, has := langs[lang]; has {
+				iter(pluralRangesData{
+					lang:   lang,
+					ranges: r.Ranges,
+				})
+			}
+		}
+	}
+}
+
 func languages(data *cldr.Data) map[string]struct{} {
 	langs := make(map[string]struct{})
 	for _, id := range data.Identities {
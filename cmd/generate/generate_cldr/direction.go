@@ -0,0 +1,140 @@
+package generate_cldr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/cldr"
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+var (
+	_ generator.Snippet     = (*direction)(nil)
+	_ generator.TestSnippet = (*direction)(nil)
+)
+
+type direction struct {
+	packageName string
+	directions  *directionLookupVar
+	samples     []directionSample
+}
+
+// directionSample is the character order of a locale in the CLDR data, which
+// is checked against the generated lookup.
+type directionSample struct {
+	tag         string
+	rightToLeft bool
+}
+
+func newDirection(packageName string, directions *directionLookupVar, data *cldr.Data) *direction {
+	// Only the locales which are part of the data are checked, so the test also
+	// works with an excerpt of the data.
+	var samples []directionSample
+	for _, locale := range []string{"ar", "ar-EG", "fa", "he", "de", "en"} {
+		if id, has := data.Identities[locale]; has && !skipIdentity(id) {
+			samples = append(samples, directionSample{
+				tag:         normalizeIdentity(id).String(),
+				rightToLeft: data.CharacterOrder(id) == "right-to-left",
+			})
+		}
+	}
+
+	return &direction{
+		packageName: packageName,
+		directions:  directions,
+		samples:     samples,
+	}
+}
+
+func (d *direction) Imports() []string {
+	return nil
+}
+
+func (d *direction) Generate(p *generator.Printer) {
+	p.Println(`// Direction specifies the order in which the characters of a locale are written.`)
+	p.Println(`type Direction int`)
+	p.Println()
+	p.Println(`// Available directions.`)
+	p.Println(`const (`)
+	p.Println(`	LeftToRight Direction = iota`)
+	p.Println(`	RightToLeft`)
+	p.Println(`)`)
+	p.Println()
+	p.Println(`// String returns the CLDR name of the direction.`)
+	p.Println(`func (d Direction) String() string {`)
+	p.Println(`	switch d {`)
+	p.Println(`	case LeftToRight:`)
+	p.Println(`		return "left-to-right"`)
+	p.Println(`	case RightToLeft:`)
+	p.Println(`		return "right-to-left"`)
+	p.Println(`	default:`)
+	p.Println(`		return ""`)
+	p.Println(`	}`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`// Direction returns the character order of the locale, which is either left-to-right`)
+	p.Println(`// (e.g. English) or right-to-left (e.g. Arabic).`)
+	p.Println(`func (l Locale) Direction() Direction {`)
+	p.Println(`	if `, d.directions.name, `.rightToLeft(tagID(l)) {`)
+	p.Println(`		return RightToLeft`)
+	p.Println(`	}`)
+	p.Println(`	return LeftToRight`)
+	p.Println(`}`)
+}
+
+func (d *direction) TestImports() []string {
+	return nil
+}
+
+func (d *direction) GenerateTest(p *generator.Printer) {
+	newLocale := "NewLocale"
+	if strings.ToLower(d.packageName) == "locale" {
+		newLocale = "New"
+	}
+
+	p.Println(`func TestDirection(t *testing.T) {`)
+	p.Println(`	for _, id := range `, d.directions.name, ` {`)
+	p.Println(`		if dir := Locale(id).Direction(); dir != RightToLeft {`)
+	p.Println(`			t.Errorf("unexpected direction for %s: %s", Locale(id), dir)`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	loc, err := `, newLocale, `("und")`)
+	p.Println(`	switch {`)
+	p.Println(`	case err != nil:`)
+	p.Println(`		t.Errorf("unexpected error: %v", err)`)
+	p.Println(`	case loc.Direction() != LeftToRight:`)
+	p.Println(`		t.Errorf("unexpected direction for the root locale: %s", loc.Direction())`)
+	p.Println(`	}`)
+	p.Println(`}`)
+
+	if len(d.samples) == 0 {
+		return
+	}
+
+	p.Println()
+	p.Println(`func TestCLDRDirection(t *testing.T) {`)
+	p.Println(`	tests := []struct {`)
+	p.Println(`		tag      string`)
+	p.Println(`		expected Direction`)
+	p.Println(`	}{`)
+	for _, sample := range d.samples {
+		expected := "LeftToRight"
+		if sample.rightToLeft {
+			expected = "RightToLeft"
+		}
+		p.Println(`		{tag: `, fmt.Sprintf("%q", sample.tag), `, expected: `, expected, `},`)
+	}
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	for _, test := range tests {`)
+	p.Println(`		loc, err := `, newLocale, `(test.tag)`)
+	p.Println(`		switch {`)
+	p.Println(`		case err != nil:`)
+	p.Println(`			t.Errorf("unexpected error for %s: %v", test.tag, err)`)
+	p.Println(`		case loc.Direction() != test.expected:`)
+	p.Println(`			t.Errorf("unexpected direction for %s: %s", test.tag, loc.Direction())`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
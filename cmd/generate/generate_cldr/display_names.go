@@ -0,0 +1,232 @@
+package generate_cldr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/cldr"
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+var (
+	_ generator.Snippet     = (*displayNames)(nil)
+	_ generator.TestSnippet = (*displayNames)(nil)
+)
+
+type displayNames struct {
+	packageName string
+	names       *displayNameLookupVar
+	samples     []displayNameSample
+}
+
+// displayNameSample is a display name of the CLDR data, which is checked against
+// the generated lookup.
+type displayNameSample struct {
+	currency bool // currency code or locale tag
+	code     string
+	in       string
+	expected string
+}
+
+func newDisplayNames(packageName string, names *displayNameLookupVar, data *cldr.Data) *displayNames {
+	samples := []displayNameSample{
+		{code: "de", in: "de"},
+		{code: "de-AT", in: "de"},
+		{code: "en-GB", in: "en"},
+		{code: "fr", in: "de"},
+		{currency: true, code: "EUR", in: "de"},
+		{currency: true, code: "USD", in: "en"},
+	}
+
+	// Only the samples with a name in the data are checked, so the test also
+	// works with an excerpt of the data.
+	var defined []displayNameSample
+	for _, sample := range samples {
+		in, has := data.Identities[sample.in]
+		if !has {
+			continue
+		}
+		key := func(names cldr.DisplayNames) string { return names.Languages[sample.code] }
+		if sample.currency {
+			key = func(names cldr.DisplayNames) string { return names.Currencies[sample.code] }
+		}
+		if sample.expected = data.DisplayName(in, key); sample.expected != "" {
+			defined = append(defined, sample)
+		}
+	}
+
+	return &displayNames{
+		packageName: packageName,
+		names:       names,
+		samples:     defined,
+	}
+}
+
+func (n *displayNames) Imports() []string {
+	return []string{"strings"}
+}
+
+func (n *displayNames) Generate(p *generator.Printer) {
+	p.Println(`const (`)
+	p.Println(`	defaultLocalePattern   = "{0} ({1})"`)
+	p.Println(`	defaultLocaleSeparator = "{0}, {1}"`)
+	p.Println(`)`)
+	p.Println()
+	p.Println(`// DisplayName returns the name of the subject locale localized for the given locale,`)
+	p.Println(`// e.g. "Deutsch (Österreich)" for de-AT in German. If there is no localized name for`)
+	p.Println(`// a subtag, the subtag itself will be used.`)
+	p.Println(`func DisplayName(subject Locale, in Locale) string {`)
+	p.Println(`	return lookupDisplayName(subject, in, `, n.names.name, `)`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`// CurrencyName returns the name of the currency with the given ISO 4217 code (e.g. "EUR")`)
+	p.Println(`// localized for the given locale. If there is no localized name, the code will be`)
+	p.Println(`// returned.`)
+	p.Println(`func CurrencyName(code string, in Locale) string {`)
+	p.Println(`	if name := `, n.names.name, `.name(in, currencyName, code); name != "" {`)
+	p.Println(`		return name`)
+	p.Println(`	}`)
+	p.Println(`	return code`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`func lookupDisplayName(subject Locale, in Locale, lookup displayNameLookup) string {`)
+	p.Println(`	nameOr := func(kind displayNameKind, code string, fallback string) string {`)
+	p.Println(`		if name := lookup.name(in, kind, code); name != "" {`)
+	p.Println(`			return name`)
+	p.Println(`		}`)
+	p.Println(`		return fallback`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	// Combinations of subtags can have their own name (e.g. "British English"`)
+	p.Println(`	// for en-GB), so we try to find the longest match first.`)
+	p.Println(`	lang, script, region := subject.Subtags()`)
+	p.Println(`	name := ""`)
+	p.Println(`	if script != "" && region != "" {`)
+	p.Println(`		if name = lookup.name(in, languageName, lang+"-"+script+"-"+region); name != "" {`)
+	p.Println(`			script, region = "", ""`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`	if name == "" && script != "" {`)
+	p.Println(`		if name = lookup.name(in, languageName, lang+"-"+script); name != "" {`)
+	p.Println(`			script = ""`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`	if name == "" && region != "" {`)
+	p.Println(`		if name = lookup.name(in, languageName, lang+"-"+region); name != "" {`)
+	p.Println(`			region = ""`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`	if name == "" {`)
+	p.Println(`		name = nameOr(languageName, lang, lang)`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	qualifiers := ""`)
+	p.Println(`	if script != "" {`)
+	p.Println(`		qualifiers = nameOr(scriptName, script, script)`)
+	p.Println(`	}`)
+	p.Println(`	if region != "" {`)
+	p.Println(`		regionName := nameOr(regionName, region, region)`)
+	p.Println(`		if qualifiers == "" {`)
+	p.Println(`			qualifiers = regionName`)
+	p.Println(`		} else {`)
+	p.Println(`			qualifiers = formatDisplayPattern(nameOr(localeSeparator, "", defaultLocaleSeparator), qualifiers, regionName)`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	if qualifiers == "" {`)
+	p.Println(`		return name`)
+	p.Println(`	}`)
+	p.Println(`	return formatDisplayPattern(nameOr(localePattern, "", defaultLocalePattern), name, qualifiers)`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`func formatDisplayPattern(pattern string, arg0 string, arg1 string) string {`)
+	p.Println(`	return strings.NewReplacer("{0}", arg0, "{1}", arg1).Replace(pattern)`)
+	p.Println(`}`)
+}
+
+func (n *displayNames) TestImports() []string {
+	return nil
+}
+
+func (n *displayNames) GenerateTest(p *generator.Printer) {
+	newLocale := "NewLocale"
+	if strings.ToLower(n.packageName) == "locale" {
+		newLocale = "New"
+	}
+
+	p.Println(`func TestDisplayName(t *testing.T) {`)
+	p.Println(`	mustLocale := func(tag string) Locale {`)
+	p.Println(`		loc, err := `, newLocale, `(tag)`)
+	p.Println(`		if err != nil {`)
+	p.Println(`			t.Fatalf("unexpected error for %s: %v", tag, err)`)
+	p.Println(`		}`)
+	p.Println(`		return loc`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	de, deAT, en := mustLocale("de"), mustLocale("de-AT"), mustLocale("en")`)
+	p.Println(`	lookup := displayNameLookup{`)
+	p.Println(`		tagID(root): "\x00p\x01{0} ({1})\x00x\x01{0}, {1}",`)
+	p.Println(`		tagID(de):   "\x00lde\x01Deutsch\x00lde-CH\x01Schweizer Hochdeutsch\x00rAT\x01Österreich\x00sLatn\x01Lateinisch",`)
+	p.Println(`		tagID(en):   "\x00lde\x01German\x00rAT\x01Austria",`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	tests := []struct {`)
+	p.Println(`		subject  string`)
+	p.Println(`		in       Locale`)
+	p.Println(`		expected string`)
+	p.Println(`	}{`)
+	p.Println(`		{subject: "de", in: de, expected: "Deutsch"},`)
+	p.Println(`		{subject: "de-AT", in: de, expected: "Deutsch (Österreich)"},`)
+	p.Println(`		{subject: "de-AT", in: deAT, expected: "Deutsch (Österreich)"},`)
+	p.Println(`		{subject: "de-AT", in: en, expected: "German (Austria)"},`)
+	p.Println(`		{subject: "de-CH", in: de, expected: "Schweizer Hochdeutsch"},`)
+	p.Println(`		{subject: "de-CH", in: en, expected: "German (CH)"},`)
+	p.Println(`		{subject: "sr-Latn-BA", in: de, expected: "sr (Lateinisch, BA)"},`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	for _, test := range tests {`)
+	p.Println(`		if name := lookupDisplayName(mustLocale(test.subject), test.in, lookup); name != test.expected {`)
+	p.Println(`			t.Errorf("unexpected display name for %s in %s: %q", test.subject, test.in, name)`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`}`)
+
+	if len(n.samples) == 0 {
+		return
+	}
+
+	p.Println()
+	p.Println(`func TestCLDRDisplayNames(t *testing.T) {`)
+	p.Println(`	tests := []struct {`)
+	p.Println(`		currency bool`)
+	p.Println(`		code     string`)
+	p.Println(`		in       string`)
+	p.Println(`		expected string`)
+	p.Println(`	}{`)
+	for _, sample := range n.samples {
+		p.Println(`		{currency: `, sample.currency, `, code: `, fmt.Sprintf("%q", sample.code), `, in: `, fmt.Sprintf("%q", sample.in), `, expected: `, fmt.Sprintf("%q", sample.expected), `},`)
+	}
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	for _, test := range tests {`)
+	p.Println(`		in, err := `, newLocale, `(test.in)`)
+	p.Println(`		if err != nil {`)
+	p.Println(`			t.Fatalf("unexpected error for %s: %v", test.in, err)`)
+	p.Println(`		}`)
+	p.Println()
+	p.Println(`		var name string`)
+	p.Println(`		if test.currency {`)
+	p.Println(`			name = CurrencyName(test.code, in)`)
+	p.Println(`		} else {`)
+	p.Println(`			subject, err := `, newLocale, `(test.code)`)
+	p.Println(`			if err != nil {`)
+	p.Println(`				t.Fatalf("unexpected error for %s: %v", test.code, err)`)
+	p.Println(`			}`)
+	p.Println(`			name = DisplayName(subject, in)`)
+	p.Println(`		}`)
+	p.Println(`		if name != test.expected {`)
+	p.Println(`			t.Errorf("unexpected name for %s in %s: %q", test.code, test.in, name)`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
@@ -16,14 +16,16 @@ var (
 
 type locale struct {
 	packageName        string
+	cldrVersion        string
 	tags               *tagLookupVar
 	parentTags         *parentTagLookupVar
 	regionContainments *regionContainmentLookupVar
 }
 
-func newLocale(packageName string, tags *tagLookupVar, parentTags *parentTagLookupVar, regionContainments *regionContainmentLookupVar) *locale {
+func newLocale(packageName string, cldrVersion string, tags *tagLookupVar, parentTags *parentTagLookupVar, regionContainments *regionContainmentLookupVar) *locale {
 	return &locale{
 		packageName:        packageName,
+		cldrVersion:        cldrVersion,
 		tags:               tags,
 		parentTags:         parentTags,
 		regionContainments: regionContainments,
@@ -43,6 +45,10 @@ func (l *locale) Generate(p *generator.Printer) {
 
 	p.Println(`const root Locale = `, l.tags.tagID(root))
 	p.Println()
+	p.Println(`// CLDRVersion is the version of the CLDR data, which the locales are generated`)
+	p.Println(`// from. It is empty if the version is unknown.`)
+	p.Println(`const CLDRVersion = `, fmt.Sprintf("%q", l.cldrVersion))
+	p.Println()
 	p.Println(`// Locale represents a reference to the data of a CLDR locale.`)
 	p.Println(`type Locale tagID`)
 	p.Println()
@@ -127,6 +133,13 @@ func (l *locale) Generate(p *generator.Printer) {
 	p.Println(`	return string(buf[:n])`)
 	p.Println(`}`)
 	p.Println()
+	p.Println(`// Parent returns the parent locale of l. The parent is either defined by the CLDR`)
+	p.Println(`// data or determined by truncating the subtags of l. The parent of the root locale`)
+	p.Println(`// is the root locale itself.`)
+	p.Println(`func (l Locale) Parent() Locale {`)
+	p.Println(`	return l.parent()`)
+	p.Println(`}`)
+	p.Println()
 	p.Println(`func (l Locale) tagIDs() (langID, scriptID, regionID) {`)
 	p.Println(`	tag := `, l.tags.name, `.tag(tagID(l))`)
 	p.Println(`	return tag.langID(), tag.scriptID(), tag.regionID()`)
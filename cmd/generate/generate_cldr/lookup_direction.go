@@ -0,0 +1,144 @@
+package generate_cldr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/cldr"
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+var (
+	_ generator.Snippet     = (*directionLookup)(nil)
+	_ generator.TestSnippet = (*directionLookup)(nil)
+)
+
+type directionLookup struct{}
+
+func newDirectionLookup() *directionLookup {
+	return &directionLookup{}
+}
+
+func (l *directionLookup) Imports() []string {
+	return []string{"sort"}
+}
+
+func (l *directionLookup) Generate(p *generator.Printer) {
+	p.Println(`// The direction lookup is an ordered list of the tag ids of all locales which`)
+	p.Println(`// are written from right to left. All other locales are written from left to`)
+	p.Println(`// right.`)
+	p.Println(`type directionLookup []tagID`)
+	p.Println()
+	p.Println(`func (l directionLookup) rightToLeft(id tagID) bool {`)
+	p.Println(`	idx := sort.Search(len(l), func(i int) bool {`)
+	p.Println(`		return l[i] >= id`)
+	p.Println(`	})`)
+	p.Println(`	return idx < len(l) && l[idx] == id`)
+	p.Println(`}`)
+}
+
+func (l *directionLookup) TestImports() []string {
+	return nil
+}
+
+func (l *directionLookup) GenerateTest(p *generator.Printer) {
+	p.Println(`func TestDirectionLookup(t *testing.T) {`)
+	p.Println(`	lookup := directionLookup{2, 4, 5}`)
+	p.Println()
+	p.Println(`	for id := tagID(1); id <= 6; id++ {`)
+	p.Println(`		expected := id == 2 || id == 4 || id == 5`)
+	p.Println(`		if rtl := lookup.rightToLeft(id); rtl != expected {`)
+	p.Println(`			t.Errorf("unexpected direction for %d: %v", id, rtl)`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
+
+var (
+	_ generator.Snippet     = (*directionLookupVar)(nil)
+	_ generator.TestSnippet = (*directionLookupVar)(nil)
+)
+
+type directionLookupVar struct {
+	name string
+	typ  *directionLookup
+	tags *tagLookupVar
+	ids  []cldr.Identity
+}
+
+func newDirectionLookupVar(name string, typ *directionLookup, tags *tagLookupVar, data *cldr.Data) *directionLookupVar {
+	var ids []cldr.Identity
+	for _, id := range data.Identities {
+		if !skipIdentity(id) && data.CharacterOrder(id) == "right-to-left" {
+			ids = append(ids, normalizeIdentity(id))
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return identityLess(ids[i], ids[j])
+	})
+
+	return &directionLookupVar{
+		name: name,
+		typ:  typ,
+		tags: tags,
+		ids:  ids,
+	}
+}
+
+func (v *directionLookupVar) Imports() []string {
+	return nil
+}
+
+func (v *directionLookupVar) Generate(p *generator.Printer) {
+	digitsPerID := v.tags.typ.idBits / 4
+	perLine := int(lineLength / (digitsPerID + 4)) // additional "0x" and ", "
+
+	hex := func(id cldr.Identity) string {
+		return fmt.Sprintf("%#0[2]*[1]x", v.tags.tagID(id), digitsPerID)
+	}
+
+	p.Println(`var `, v.name, ` = directionLookup{ // `, len(v.ids), ` items, `, uint(len(v.ids))*digitsPerID/2, ` bytes`)
+
+	for i := 0; i < len(v.ids); i += perLine {
+		n := i + perLine
+		if n > len(v.ids) {
+			n = len(v.ids)
+		}
+
+		p.Print(`	`)
+		for _, id := range v.ids[i:n] {
+			p.Print(hex(id), `, `)
+		}
+		p.Print(`// `)
+		for k, id := range v.ids[i:n] {
+			p.Print(id.String())
+			if k+i < n-1 {
+				p.Print(`, `)
+			}
+		}
+		p.Println()
+	}
+
+	p.Println(`}`)
+}
+
+func (v *directionLookupVar) TestImports() []string {
+	return nil
+}
+
+func (v *directionLookupVar) GenerateTest(p *generator.Printer) {
+	p.Println(`func Test`, strings.Title(v.name), `(t *testing.T) {`)
+	p.Println(`	for i := 1; i < len(`, v.name, `); i++ {`)
+	p.Println(`		if `, v.name, `[i-1] >= `, v.name, `[i] {`)
+	p.Println(`			t.Fatalf("unordered tag ids at index %d", i)`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`	for _, id := range `, v.name, ` {`)
+	p.Println(`		if !`, v.name, `.rightToLeft(id) {`)
+	p.Println(`			t.Errorf("expected tag id %d to be right-to-left", uint(id))`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
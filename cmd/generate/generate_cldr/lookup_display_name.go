@@ -0,0 +1,284 @@
+package generate_cldr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/cldr"
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+var (
+	_ generator.Snippet     = (*displayNameLookup)(nil)
+	_ generator.TestSnippet = (*displayNameLookup)(nil)
+)
+
+type displayNameLookup struct {
+	kinds []displayNameKind
+}
+
+type displayNameKind struct {
+	enumerator string
+	prefix     byte
+	names      func(cldr.DisplayNames) map[string]string
+	pattern    func(cldr.DisplayNames) string
+}
+
+func newDisplayNameLookup() *displayNameLookup {
+	return &displayNameLookup{
+		kinds: []displayNameKind{
+			{
+				enumerator: "languageName",
+				prefix:     'l',
+				names:      func(n cldr.DisplayNames) map[string]string { return n.Languages },
+			},
+			{
+				enumerator: "scriptName",
+				prefix:     's',
+				names:      func(n cldr.DisplayNames) map[string]string { return n.Scripts },
+			},
+			{
+				enumerator: "regionName",
+				prefix:     'r',
+				names:      func(n cldr.DisplayNames) map[string]string { return n.Territories },
+			},
+			{
+				enumerator: "currencyName",
+				prefix:     'c',
+				names:      func(n cldr.DisplayNames) map[string]string { return n.Currencies },
+			},
+			{
+				enumerator: "localePattern",
+				prefix:     'p',
+				pattern:    func(n cldr.DisplayNames) string { return n.LocalePattern },
+			},
+			{
+				enumerator: "localeSeparator",
+				prefix:     'x',
+				pattern:    func(n cldr.DisplayNames) string { return n.LocaleSeparator },
+			},
+		},
+	}
+}
+
+func (l *displayNameLookup) record(kind displayNameKind, code string, name string) string {
+	return "\x00" + string(kind.prefix) + code + "\x01" + name
+}
+
+func (l *displayNameLookup) Imports() []string {
+	return []string{"strings"}
+}
+
+func (l *displayNameLookup) Generate(p *generator.Printer) {
+	maxEnumerator := 0
+	for _, kind := range l.kinds {
+		if len(kind.enumerator) > maxEnumerator {
+			maxEnumerator = len(kind.enumerator)
+		}
+	}
+
+	p.Println(`// A display name kind specifies the type of a display name. It is used as a`)
+	p.Println(`// prefix for the codes in the display name lookup.`)
+	p.Println(`type displayNameKind byte`)
+	p.Println()
+	p.Println(`const (`)
+	for _, kind := range l.kinds {
+		gap := strings.Repeat(" ", maxEnumerator-len(kind.enumerator))
+		p.Println(`	`, kind.enumerator, gap, ` displayNameKind = '`, string(kind.prefix), `'`)
+	}
+	p.Println(`)`)
+	p.Println()
+	p.Println(`// The display name lookup maps a tag id to the display names which are defined`)
+	p.Println(`// for this locale. The names are encoded as a sequence of records, where each record`)
+	p.Println(`// has the form "\x00<kind><code>\x01<name>". Names which are inherited from a parent`)
+	p.Println(`// locale are omitted.`)
+	p.Println(`type displayNameLookup map[tagID]string`)
+	p.Println()
+	p.Println(`func (l displayNameLookup) name(loc Locale, kind displayNameKind, code string) string {`)
+	p.Println(`	key := "\x00" + string(kind) + code + "\x01"`)
+	p.Println(`	for {`)
+	p.Println(`		if names, has := l[tagID(loc)]; has {`)
+	p.Println(`			if idx := strings.Index(names, key); idx >= 0 {`)
+	p.Println(`				name := names[idx+len(key):]`)
+	p.Println(`				if end := strings.IndexByte(name, 0); end >= 0 {`)
+	p.Println(`					name = name[:end]`)
+	p.Println(`				}`)
+	p.Println(`				return name`)
+	p.Println(`			}`)
+	p.Println(`		}`)
+	p.Println(`		if loc == root {`)
+	p.Println(`			return ""`)
+	p.Println(`		}`)
+	p.Println(`		loc = loc.parent()`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
+
+func (l *displayNameLookup) TestImports() []string {
+	return nil
+}
+
+func (l *displayNameLookup) GenerateTest(p *generator.Printer) {
+	p.Println(`func TestDisplayNameLookup(t *testing.T) {`)
+	p.Println(`	lookup := displayNameLookup{`)
+	p.Println(`		tagID(root): "\x00lfoo\x01Foo\x00lfoo-bar\x01Foo Bar\x00rbar\x01Bar",`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	if name := lookup.name(root, languageName, "foo"); name != "Foo" {`)
+	p.Println(`		t.Errorf("unexpected name for foo: %q", name)`)
+	p.Println(`	}`)
+	p.Println(`	if name := lookup.name(root, languageName, "foo-bar"); name != "Foo Bar" {`)
+	p.Println(`		t.Errorf("unexpected name for foo-bar: %q", name)`)
+	p.Println(`	}`)
+	p.Println(`	if name := lookup.name(root, regionName, "bar"); name != "Bar" {`)
+	p.Println(`		t.Errorf("unexpected name for bar: %q", name)`)
+	p.Println(`	}`)
+	p.Println(`	if name := lookup.name(root, languageName, "bar"); name != "" {`)
+	p.Println(`		t.Errorf("unexpected name for bar: %q", name)`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
+
+var (
+	_ generator.Snippet = (*displayNameLookupVar)(nil)
+)
+
+type displayNameLookupVar struct {
+	name string
+	typ  *displayNameLookup
+	tags *tagLookupVar
+	data []displayNameData
+}
+
+type displayNameData struct {
+	id      cldr.Identity
+	records []string
+}
+
+func newDisplayNameLookupVar(name string, typ *displayNameLookup, tags *tagLookupVar, data *cldr.Data) *displayNameLookupVar {
+	// Only the names of supported languages, scripts, and regions are part
+	// of the lookup.
+	langs := make(map[string]struct{})
+	scripts := make(map[string]struct{})
+	regions := make(map[string]struct{})
+	forEachIdentity(data, func(id cldr.Identity) {
+		langs[id.Language] = struct{}{}
+		scripts[id.Script] = struct{}{}
+		regions[id.Territory] = struct{}{}
+	})
+
+	supported := map[string]func(string) bool{
+		"languageName": func(code string) bool {
+			lang, _, _ := strings.Cut(code, "-")
+			_, has := langs[lang]
+			return has
+		},
+		"scriptName": func(code string) bool {
+			_, has := scripts[code]
+			return has
+		},
+		"regionName": func(code string) bool {
+			_, has := regions[code]
+			return has
+		},
+	}
+
+	var nameData []displayNameData
+	for _, id := range data.Identities {
+		names, has := data.DisplayNames[id.String()]
+		if !has || skipIdentity(id) {
+			continue
+		}
+
+		var parent cldr.Identity
+		if !id.IsRoot() {
+			parent = data.ParentIdentity(id)
+		}
+		inherited := func(key func(cldr.DisplayNames) string) bool {
+			return !id.IsRoot() && data.DisplayName(parent, key) == key(names)
+		}
+
+		var records []string
+		for _, kind := range typ.kinds {
+			kind := kind
+			if kind.pattern != nil {
+				if pattern := kind.pattern(names); pattern != "" && !inherited(kind.pattern) {
+					records = append(records, typ.record(kind, "", pattern))
+				}
+				continue
+			}
+
+			for code, displayName := range kind.names(names) {
+				if isSupported, has := supported[kind.enumerator]; has && !isSupported(code) {
+					continue
+				}
+				code := code
+				if !inherited(func(n cldr.DisplayNames) string { return kind.names(n)[code] }) {
+					records = append(records, typ.record(kind, code, displayName))
+				}
+			}
+		}
+
+		if len(records) != 0 {
+			sort.Strings(records)
+			nameData = append(nameData, displayNameData{
+				id:      normalizeIdentity(id),
+				records: records,
+			})
+		}
+	}
+
+	sort.Slice(nameData, func(i, j int) bool {
+		return identityLess(nameData[i].id, nameData[j].id)
+	})
+
+	return &displayNameLookupVar{
+		name: name,
+		typ:  typ,
+		tags: tags,
+		data: nameData,
+	}
+}
+
+func (v *displayNameLookupVar) Imports() []string {
+	return nil
+}
+
+func (v *displayNameLookupVar) Generate(p *generator.Printer) {
+	bytes := 0
+	for _, data := range v.data {
+		for _, rec := range data.records {
+			bytes += len(rec)
+		}
+	}
+
+	p.Println(`var `, v.name, ` = displayNameLookup{ // `, len(v.data), ` items, `, bytes, ` bytes`)
+
+	for _, data := range v.data {
+		tagID := fmt.Sprintf("%#0[2]*[1]x", v.tags.tagID(data.id), v.tags.typ.idBits/4)
+		p.Println(`	`, tagID, `: "" + // `, data.id.String())
+
+		count := 0
+		p.Print(`		`)
+		for i, rec := range data.records {
+			quoted := strconv.Quote(rec)
+			if count != 0 && count+len(quoted) > lineLength {
+				p.Println(` +`)
+				p.Print(`		`)
+				count = 0
+			}
+			if count != 0 {
+				p.Print(` + `)
+			}
+			p.Print(quoted)
+			count += len(quoted)
+			if i == len(data.records)-1 {
+				p.Println(`,`)
+			}
+		}
+	}
+
+	p.Println(`}`)
+}
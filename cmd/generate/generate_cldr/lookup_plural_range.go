@@ -0,0 +1,149 @@
+package generate_cldr
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/liblxn/lxnc/internal/cldr"
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+var (
+	_ generator.Snippet     = (*pluralRangeLookup)(nil)
+	_ generator.TestSnippet = (*pluralRangeLookup)(nil)
+)
+
+type pluralRangeLookup struct {
+	category *pluralCategory
+}
+
+func newPluralRangeLookup(category *pluralCategory) *pluralRangeLookup {
+	return &pluralRangeLookup{category: category}
+}
+
+func (l *pluralRangeLookup) bits() uint {
+	bits := 3 * l.category.bits
+	switch {
+	case bits <= 8:
+		return 8
+	case bits <= 16:
+		return 16
+	case bits <= 32:
+		return 32
+	default:
+		panic("invalid plural range bits")
+	}
+}
+
+func (l *pluralRangeLookup) newPluralRange(start, end, result uint) uint {
+	return (start << (2 * l.category.bits)) | (end << l.category.bits) | result
+}
+
+func (l *pluralRangeLookup) Imports() []string {
+	return nil
+}
+
+func (l *pluralRangeLookup) Generate(p *generator.Printer) {
+	categoryMask := fmt.Sprintf("%#x", (1<<l.category.bits)-1)
+
+	p.Println(`// A plural range consists of the plural categories of the range start, the range`)
+	p.Println(`// end, and the resulting category of the whole range.`)
+	p.Println(`type pluralRange uint`, l.bits())
+	p.Println()
+	p.Println(`func (r pluralRange) start() uint  { return uint(r>>`, 2*l.category.bits, `) & `, categoryMask, ` }`)
+	p.Println(`func (r pluralRange) end() uint    { return uint(r>>`, l.category.bits, `) & `, categoryMask, ` }`)
+	p.Println(`func (r pluralRange) result() uint { return uint(r) & `, categoryMask, ` }`)
+	p.Println()
+	p.Println(`type pluralRangeLookup map[langID][]pluralRange`)
+}
+
+func (l *pluralRangeLookup) TestImports() []string {
+	return nil
+}
+
+func (l *pluralRangeLookup) GenerateTest(p *generator.Printer) {
+	p.Println(`func TestPluralRange(t *testing.T) {`)
+	p.Println(`	const rng pluralRange = `, fmt.Sprintf("%#x", l.newPluralRange(1, 3, 5)))
+	p.Println()
+	p.Println(`	if start := rng.start(); start != 1 {`)
+	p.Println(`		t.Errorf("unexpected start category: %d", start)`)
+	p.Println(`	}`)
+	p.Println(`	if end := rng.end(); end != 3 {`)
+	p.Println(`		t.Errorf("unexpected end category: %d", end)`)
+	p.Println(`	}`)
+	p.Println(`	if result := rng.result(); result != 5 {`)
+	p.Println(`		t.Errorf("unexpected result category: %d", result)`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
+
+var (
+	_ generator.Snippet = (*pluralRangeLookupVar)(nil)
+)
+
+type pluralRangeLookupVar struct {
+	name     string
+	typ      *pluralRangeLookup
+	category *pluralCategory
+	langs    *langLookupVar
+	data     []pluralRangesData
+}
+
+func newPluralRangeLookupVar(name string, typ *pluralRangeLookup, category *pluralCategory, langs *langLookupVar, data *cldr.Data) *pluralRangeLookupVar {
+	rangesData := make([]pluralRangesData, 0, 8)
+	forEachPluralRanges(data, func(data pluralRangesData) {
+		if len(data.ranges) != 0 {
+			rangesData = append(rangesData, data)
+		}
+	})
+
+	sort.Slice(rangesData, func(i, j int) bool {
+		return rangesData[i].lang < rangesData[j].lang
+	})
+
+	return &pluralRangeLookupVar{
+		name:     name,
+		typ:      typ,
+		category: category,
+		langs:    langs,
+		data:     rangesData,
+	}
+}
+
+func (v *pluralRangeLookupVar) categoryOf(cldrCategory string) uint {
+	for _, category := range [...]uint{v.category.zero, v.category.one, v.category.two, v.category.few, v.category.many, v.category.other} {
+		if v.category.cldrConstantOf(category) == cldrCategory {
+			return category
+		}
+	}
+	panic(fmt.Sprintf("invalid plural category %q", cldrCategory))
+}
+
+func (v *pluralRangeLookupVar) Imports() []string {
+	return nil
+}
+
+func (v *pluralRangeLookupVar) Generate(p *generator.Printer) {
+	bits := v.typ.bits()
+	hex := func(v, bits uint) string {
+		return fmt.Sprintf("%#0[2]*[1]x", v, (bits+3)/4)
+	}
+
+	count := 0
+	for _, data := range v.data {
+		count += len(data.ranges)
+	}
+
+	p.Println(`var `, v.name, ` = pluralRangeLookup{ // `, len(v.data), ` items, `, uint(count)*bits/8, ` bytes`)
+	for _, data := range v.data {
+		p.Print(`	`, hex(v.langs.langID(data.lang), v.langs.typ.idBits), `: {`)
+		for i, rng := range data.ranges {
+			if i != 0 {
+				p.Print(`, `)
+			}
+			p.Print(hex(v.typ.newPluralRange(v.categoryOf(rng.Start), v.categoryOf(rng.End), v.categoryOf(rng.Result)), bits))
+		}
+		p.Println(`}, // `, data.lang)
+	}
+	p.Println(`}`)
+}
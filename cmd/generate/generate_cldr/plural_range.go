@@ -0,0 +1,184 @@
+package generate_cldr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+var (
+	_ generator.Snippet     = (*pluralRanges)(nil)
+	_ generator.TestSnippet = (*pluralRanges)(nil)
+)
+
+type pluralRanges struct {
+	packageName string
+	typ         *pluralRangeLookup
+	category    *pluralCategory
+	ranges      *pluralRangeLookupVar
+}
+
+func newPluralRanges(packageName string, typ *pluralRangeLookup, category *pluralCategory, ranges *pluralRangeLookupVar) *pluralRanges {
+	return &pluralRanges{
+		packageName: packageName,
+		typ:         typ,
+		category:    category,
+		ranges:      ranges,
+	}
+}
+
+func (pr *pluralRanges) Imports() []string {
+	return nil
+}
+
+func (pr *pluralRanges) Generate(p *generator.Printer) {
+	p.Println(`// PluralRange defines the plural category of a number range (e.g. "1–3 days"), where`)
+	p.Println(`// the start and the end of the range have the given plural categories.`)
+	p.Println(`type PluralRange struct {`)
+	p.Println(`	Start  PluralCategory`)
+	p.Println(`	End    PluralCategory`)
+	p.Println(`	Result PluralCategory`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`// PluralRanges returns the plural ranges for cardinals in the given locale.`)
+	p.Println(`func PluralRanges(loc Locale) []PluralRange {`)
+	p.Println(`	return lookupPluralRanges(loc, `, pr.ranges.name, `)`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`// PluralRangeCategory returns the plural category of a number range in the given`)
+	p.Println(`// locale, where start and end are the plural categories of the range bounds. If`)
+	p.Println(`// the locale does not define a category for the range, the end category will be`)
+	p.Println(`// returned.`)
+	p.Println(`func PluralRangeCategory(loc Locale, start PluralCategory, end PluralCategory) PluralCategory {`)
+	p.Println(`	for _, rng := range PluralRanges(loc) {`)
+	p.Println(`		if rng.Start == start && rng.End == end {`)
+	p.Println(`			return rng.Result`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`	return end`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`func lookupPluralRanges(loc Locale, lookup pluralRangeLookup) []PluralRange {`)
+	p.Println(`	if loc == 0 {`)
+	p.Println(`		panic("invalid locale")`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	lang, _, _ := loc.tagIDs()`)
+	p.Println(`	ranges := lookup[lang]`)
+	p.Println(`	if len(ranges) == 0 {`)
+	p.Println(`		return nil`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	res := make([]PluralRange, len(ranges))`)
+	p.Println(`	for i, rng := range ranges {`)
+	p.Println(`		res[i] = PluralRange{`)
+	p.Println(`			Start:  PluralCategory(rng.start()),`)
+	p.Println(`			End:    PluralCategory(rng.end()),`)
+	p.Println(`			Result: PluralCategory(rng.result()),`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`	return res`)
+	p.Println(`}`)
+}
+
+func (pr *pluralRanges) TestImports() []string {
+	return []string{"reflect"}
+}
+
+func (pr *pluralRanges) GenerateTest(p *generator.Printer) {
+	newLocale := "NewLocale"
+	if strings.ToLower(pr.packageName) == "locale" {
+		newLocale = "New"
+	}
+
+	one := pr.category.enumeratorOf(pr.category.one)
+	other := pr.category.enumeratorOf(pr.category.other)
+	rng := func(start, end, result uint) string {
+		return fmt.Sprintf("%#x", pr.typ.newPluralRange(start, end, result))
+	}
+
+	p.Println(`func TestLookupPluralRanges(t *testing.T) {`)
+	p.Println(`	loc, err := `, newLocale, `("en")`)
+	p.Println(`	if err != nil {`)
+	p.Println(`		t.Fatalf("unexpected error: %v", err)`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	lang, _, _ := loc.tagIDs()`)
+	p.Println(`	lookup := pluralRangeLookup{`)
+	p.Println(`		lang: {`, rng(pr.category.one, pr.category.other, pr.category.other), `, `, rng(pr.category.other, pr.category.one, pr.category.one), `},`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	expected := []PluralRange{`)
+	p.Println(`		{Start: `, one, `, End: `, other, `, Result: `, other, `},`)
+	p.Println(`		{Start: `, other, `, End: `, one, `, Result: `, one, `},`)
+	p.Println(`	}`)
+	p.Println(`	if ranges := lookupPluralRanges(loc, lookup); !reflect.DeepEqual(ranges, expected) {`)
+	p.Println(`		t.Errorf("unexpected plural ranges: %+v", ranges)`)
+	p.Println(`	}`)
+	p.Println(`	if ranges := lookupPluralRanges(loc, pluralRangeLookup{}); ranges != nil {`)
+	p.Println(`		t.Errorf("unexpected plural ranges: %+v", ranges)`)
+	p.Println(`	}`)
+	p.Println(`}`)
+	p.Println()
+	p.Println(`func TestPluralRangeCategory(t *testing.T) {`)
+	p.Println(`	for _, data := range `, pr.ranges.name, ` {`)
+	p.Println(`		for _, rng := range data {`)
+	p.Println(`			if rng.result() > `, pr.category.other, ` {`)
+	p.Println(`				t.Errorf("invalid plural range result: %d", rng.result())`)
+	p.Println(`			}`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	loc, err := `, newLocale, `("und")`)
+	p.Println(`	switch {`)
+	p.Println(`	case err != nil:`)
+	p.Println(`		t.Errorf("unexpected error: %v", err)`)
+	p.Println(`	case PluralRangeCategory(loc, `, other, `, `, one, `) != `, one, `:`)
+	p.Println(`		t.Errorf("unexpected fallback category for the root locale")`)
+	p.Println(`	}`)
+	p.Println(`}`)
+
+	// The ranges of a few languages are checked against the CLDR data, so a
+	// lookup which lacks them fails. Only the languages which are part of the
+	// data are checked, so the test also works with an excerpt of the data.
+	var samples []pluralRangesData
+	for _, data := range pr.ranges.data {
+		switch data.lang {
+		case "ar", "de", "en", "fr":
+			samples = append(samples, data)
+		}
+	}
+	if len(samples) == 0 {
+		return
+	}
+
+	p.Println()
+	p.Println(`func TestCLDRPluralRanges(t *testing.T) {`)
+	p.Println(`	tests := []struct {`)
+	p.Println(`		lang     string`)
+	p.Println(`		start    PluralCategory`)
+	p.Println(`		end      PluralCategory`)
+	p.Println(`		expected PluralCategory`)
+	p.Println(`	}{`)
+	for _, data := range samples {
+		for _, rng := range data.ranges {
+			start := pr.category.enumeratorOf(pr.ranges.categoryOf(rng.Start))
+			end := pr.category.enumeratorOf(pr.ranges.categoryOf(rng.End))
+			result := pr.category.enumeratorOf(pr.ranges.categoryOf(rng.Result))
+			p.Println(`		{lang: `, fmt.Sprintf("%q", data.lang), `, start: `, start, `, end: `, end, `, expected: `, result, `},`)
+		}
+	}
+	p.Println(`	}`)
+	p.Println()
+	p.Println(`	for _, test := range tests {`)
+	p.Println(`		loc, err := `, newLocale, `(test.lang)`)
+	p.Println(`		switch {`)
+	p.Println(`		case err != nil:`)
+	p.Println(`			t.Errorf("unexpected error for %s: %v", test.lang, err)`)
+	p.Println(`		case PluralRangeCategory(loc, test.start, test.end) != test.expected:`)
+	p.Println(`			t.Errorf("unexpected category for %s %d+%d: %d", test.lang, test.start, test.end, PluralRangeCategory(loc, test.start, test.end))`)
+	p.Println(`		}`)
+	p.Println(`	}`)
+	p.Println(`}`)
+}
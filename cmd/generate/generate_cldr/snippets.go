@@ -7,7 +7,7 @@ import (
 
 const lineLength = 60
 
-func FileSnippets(packageName string, data *cldr.Data) map[string]generator.Snippet {
+func FileSnippets(packageName string, cldrVersion string, data *cldr.Data) map[string]generator.Snippet {
 	// locale
 	langLookup := newLangLookup()
 	scriptLookup := newScriptLookup()
@@ -38,6 +38,14 @@ func FileSnippets(packageName string, data *cldr.Data) map[string]generator.Snip
 	moneyNumbersLookupVar := newNumbersLookupVar("moneyNumbers", numbersLookup, tagLookupVar, patternLookupVar, symbolsLookupVar, zeroLookupVar, data, currencyNumbers)
 	percentNumbersLookupVar := newNumbersLookupVar("percentNumbers", numbersLookup, tagLookupVar, patternLookupVar, symbolsLookupVar, zeroLookupVar, data, percentNumbers)
 
+	// display names
+	displayNameLookup := newDisplayNameLookup()
+	displayNameLookupVar := newDisplayNameLookupVar("displayNames", displayNameLookup, tagLookupVar, data)
+
+	// direction
+	directionLookup := newDirectionLookup()
+	directionLookupVar := newDirectionLookupVar("rtlLocales", directionLookup, tagLookupVar, data)
+
 	// plural
 	connective := newConnective()
 	pluralOperation := newPluralOperation()
@@ -49,9 +57,13 @@ func FileSnippets(packageName string, data *cldr.Data) map[string]generator.Snip
 	cardinalPluralRulesLookupVar := newPluralRuleLookupVar("cardinalRules", pluralRuleLookup, pluralCategory, langLookupVar, relationLookupVar, data, cardinalPluralRules)
 	ordinalPluralRulesLookupVar := newPluralRuleLookupVar("ordinalRules", pluralRuleLookup, pluralCategory, langLookupVar, relationLookupVar, data, ordinalPluralRules)
 
+	// plural ranges
+	pluralRangeLookup := newPluralRangeLookup(pluralCategory)
+	pluralRangeLookupVar := newPluralRangeLookupVar("pluralRanges", pluralRangeLookup, pluralCategory, langLookupVar, data)
+
 	return map[string]generator.Snippet{
 		"locale.go": generator.Snippets{
-			newLocale(packageName, tagLookupVar, parentTagLookupVar, regionContainmentLookupVar),
+			newLocale(packageName, cldrVersion, tagLookupVar, parentTagLookupVar, regionContainmentLookupVar),
 			tagLookup,
 			langLookup,
 			scriptLookup,
@@ -67,6 +79,14 @@ func FileSnippets(packageName string, data *cldr.Data) map[string]generator.Snip
 			zeroLookup,
 			numbersLookup,
 		},
+		"display_names.go": generator.Snippets{
+			newDisplayNames(packageName, displayNameLookupVar, data),
+			displayNameLookup,
+		},
+		"direction.go": generator.Snippets{
+			newDirection(packageName, directionLookupVar, data),
+			directionLookup,
+		},
 		"plural.go": generator.Snippets{
 			newPlural(pluralOperation, connective, pluralCategory, tagLookupVar, relationLookupVar, cardinalPluralRulesLookupVar, ordinalPluralRulesLookupVar),
 			connective,
@@ -75,6 +95,10 @@ func FileSnippets(packageName string, data *cldr.Data) map[string]generator.Snip
 			relationLookup,
 			pluralRuleLookup,
 		},
+		"plural_range.go": generator.Snippets{
+			newPluralRanges(packageName, pluralRangeLookup, pluralCategory, pluralRangeLookupVar),
+			pluralRangeLookup,
+		},
 		"tables.go": generator.Snippets{
 			langLookupVar,
 			scriptLookupVar,
@@ -94,6 +118,11 @@ func FileSnippets(packageName string, data *cldr.Data) map[string]generator.Snip
 			relationLookupVar,
 			cardinalPluralRulesLookupVar,
 			ordinalPluralRulesLookupVar,
+			pluralRangeLookupVar,
+
+			displayNameLookupVar,
+
+			directionLookupVar,
 		},
 	}
 }
@@ -0,0 +1,253 @@
+package generate_cldr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liblxn/lxnc/internal/cldr"
+	"github.com/liblxn/lxnc/internal/filetree"
+	"github.com/liblxn/lxnc/internal/generator"
+)
+
+// testCLDRFiles is a minimal excerpt of the CLDR data, which contains everything
+// the snippets need.
+var testCLDRFiles = map[string]string{
+	"common/main/root.xml": testLDML("root", "", `
+		<layout>
+			<orientation>
+				<characterOrder>left-to-right</characterOrder>
+			</orientation>
+		</layout>
+		<numbers>
+			<defaultNumberingSystem>latn</defaultNumberingSystem>
+			<symbols numberSystem="latn">
+				<decimal>.</decimal>
+				<group>,</group>
+				<percentSign>%</percentSign>
+				<minusSign>-</minusSign>
+			</symbols>
+			<decimalFormats numberSystem="latn">
+				<decimalFormatLength><decimalFormat><pattern>#,##0.###</pattern></decimalFormat></decimalFormatLength>
+			</decimalFormats>
+			<percentFormats numberSystem="latn">
+				<percentFormatLength><percentFormat><pattern>#,##0%</pattern></percentFormat></percentFormatLength>
+			</percentFormats>
+			<currencyFormats numberSystem="latn">
+				<currencyFormatLength><currencyFormat type="standard"><pattern>¤ #,##0.00</pattern></currencyFormat></currencyFormatLength>
+			</currencyFormats>
+		</numbers>
+	`),
+	"common/main/de.xml": testLDML("de", "", `
+		<localeDisplayNames>
+			<languages>
+				<language type="de">Deutsch</language>
+				<language type="de_AT">Österreichisches Deutsch</language>
+			</languages>
+		</localeDisplayNames>
+		<numbers>
+			<currencies>
+				<currency type="EUR">
+					<displayName>Euro</displayName>
+				</currency>
+			</currencies>
+		</numbers>
+	`),
+	"common/main/de_AT.xml": testLDML("de", "AT", ``),
+	"common/main/en.xml":    testLDML("en", "", ``),
+	"common/main/ar.xml": testLDML("ar", "", `
+		<layout>
+			<orientation>
+				<characterOrder>right-to-left</characterOrder>
+			</orientation>
+		</layout>
+	`),
+	"common/main/ar_EG.xml": testLDML("ar", "EG", ``),
+
+	"common/supplemental/numberingSystems.xml": `<?xml version="1.0" encoding="UTF-8" ?>
+		<supplementalData>
+			<numberingSystems>
+				<numberingSystem id="latn" type="numeric" digits="0123456789"/>
+			</numberingSystems>
+		</supplementalData>
+	`,
+	"common/supplemental/plurals.xml": `<?xml version="1.0" encoding="UTF-8" ?>
+		<supplementalData>
+			<plurals type="cardinal">
+				<pluralRules locales="en de">
+					<pluralRule count="one">i = 1 and v = 0 @integer 1</pluralRule>
+					<pluralRule count="other"> @integer 0, 2~16</pluralRule>
+				</pluralRules>
+				<pluralRules locales="root ar">
+					<pluralRule count="other"> @integer 0~15</pluralRule>
+				</pluralRules>
+			</plurals>
+		</supplementalData>
+	`,
+	"common/supplemental/ordinals.xml": `<?xml version="1.0" encoding="UTF-8" ?>
+		<supplementalData>
+			<plurals type="ordinal">
+				<pluralRules locales="root en de ar">
+					<pluralRule count="other"> @integer 0~15</pluralRule>
+				</pluralRules>
+			</plurals>
+		</supplementalData>
+	`,
+	"common/supplemental/pluralRanges.xml": `<?xml version="1.0" encoding="UTF-8" ?>
+		<supplementalData>
+			<plurals>
+				<pluralRanges locales="en">
+					<pluralRange start="one" end="other" result="other"/>
+					<pluralRange start="other" end="other" result="other"/>
+				</pluralRanges>
+			</plurals>
+		</supplementalData>
+	`,
+	"common/supplemental/supplementalData.xml": `<?xml version="1.0" encoding="UTF-8" ?>
+		<supplementalData>
+			<territoryContainment>
+				<group type="001" contains="150 002"/>
+				<group type="150" contains="AT"/>
+				<group type="002" contains="EG"/>
+			</territoryContainment>
+		</supplementalData>
+	`,
+	"common/supplemental/likelySubtags.xml": `<?xml version="1.0" encoding="UTF-8" ?>
+		<supplementalData>
+			<likelySubtags>
+				<likelySubtag from="de" to="de_Latn_DE"/>
+				<likelySubtag from="en" to="en_Latn_US"/>
+				<likelySubtag from="ar" to="ar_Arab_EG"/>
+			</likelySubtags>
+		</supplementalData>
+	`,
+}
+
+func testLDML(lang string, region string, body string) string {
+	identity := `<language type="` + lang + `"/>`
+	if region != "" {
+		identity += `<territory type="` + region + `"/>`
+	}
+	return `<?xml version="1.0" encoding="UTF-8" ?><ldml><identity>` + identity + `</identity>` + body + `</ldml>`
+}
+
+// generateTestPackage generates the locale package from the test data and
+// returns a function which reads the generated files.
+func generateTestPackage(t *testing.T) func(filename string) string {
+	data, err := cldr.Decode(filetree.Memory(testCLDRFiles))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	gen, err := generator.New(generator.Options{
+		OutputDirectory: dir,
+		PackageName:     "locale",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := gen.GenerateFiles(FileSnippets("locale", "44", data)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return func(filename string) string {
+		src, err := os.ReadFile(filepath.Join(dir, filename))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return string(src)
+	}
+}
+
+// tableOf returns the source of the variable with the given name.
+func tableOf(t *testing.T, tables string, name string) string {
+	start := strings.Index(tables, "var "+name+" = ")
+	if start < 0 {
+		t.Fatalf("table %s not found", name)
+	}
+	end := strings.Index(tables[start:], "\n}\n")
+	if end < 0 {
+		t.Fatalf("unterminated table %s", name)
+	}
+	return tables[start : start+end+2]
+}
+
+func TestGenerateDisplayNames(t *testing.T) {
+	generated := generateTestPackage(t)
+	table := tableOf(t, generated("tables.go"), "displayNames")
+
+	expected := []string{
+		`// de`,
+		`"\x00cEUR\x01Euro"`,
+		`"\x00lde\x01Deutsch"`,
+		`"\x00lde-AT\x01Österreichisches Deutsch"`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(table, s) {
+			t.Errorf("expected %s in display names:\n%s", s, table)
+		}
+	}
+
+	// The generated tests check real names, so a regenerated package which
+	// lacks them fails.
+	test := generated("display_names_test.go")
+	expected = []string{
+		`func TestCLDRDisplayNames(`,
+		`{currency: false, code: "de", in: "de", expected: "Deutsch"}`,
+		`{currency: false, code: "de-AT", in: "de", expected: "Österreichisches Deutsch"}`,
+		`{currency: true, code: "EUR", in: "de", expected: "Euro"}`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(test, s) {
+			t.Errorf("expected %s in display name tests:\n%s", s, test)
+		}
+	}
+}
+
+func TestGenerateRtlLocales(t *testing.T) {
+	generated := generateTestPackage(t)
+
+	// The lookup holds the locales with a right-to-left character order, which
+	// includes the locales inheriting it (ar-EG from ar).
+	table := tableOf(t, generated("tables.go"), "rtlLocales")
+	if !strings.Contains(table, "// 2 items,") || !strings.Contains(table, "// ar, ar-EG\n") {
+		t.Errorf("unexpected right-to-left locales:\n%s", table)
+	}
+
+	test := generated("direction_test.go")
+	expected := []string{
+		`func TestCLDRDirection(`,
+		`{tag: "ar", expected: RightToLeft}`,
+		`{tag: "ar-EG", expected: RightToLeft}`,
+		`{tag: "de", expected: LeftToRight}`,
+		`{tag: "en", expected: LeftToRight}`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(test, s) {
+			t.Errorf("expected %s in direction tests:\n%s", s, test)
+		}
+	}
+}
+
+func TestGeneratePluralRanges(t *testing.T) {
+	generated := generateTestPackage(t)
+
+	table := tableOf(t, generated("tables.go"), "pluralRanges")
+	if !strings.Contains(table, "// 1 items,") || !strings.Contains(table, "// en\n") {
+		t.Errorf("unexpected plural ranges:\n%s", table)
+	}
+
+	test := generated("plural_range_test.go")
+	expected := []string{
+		`func TestCLDRPluralRanges(`,
+		`{lang: "en", start: One, end: Other, expected: Other}`,
+		`{lang: "en", start: Other, end: Other, expected: Other}`,
+	}
+	for _, s := range expected {
+		if !strings.Contains(test, s) {
+			t.Errorf("expected %s in plural range tests:\n%s", s, test)
+		}
+	}
+}
@@ -97,7 +97,7 @@ func generateCldr(opts options) error {
 		return err
 	}
 
-	snippets := generate_cldr.FileSnippets(opts.packageName, data)
+	snippets := generate_cldr.FileSnippets(opts.packageName, opts.cldrVersion, data)
 	return gen.GenerateFiles(snippets)
 }
 
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// format formats the given translation files. By default, the formatted files
+// are written to stdout. With --write, the files are rewritten in place, and with
+// --diff, a diff between the original and the formatted files is printed.
+func format(opts options) {
+	if len(opts.inputFiles) == 0 {
+		fatalf("missing translation files")
+	}
+
+	failed := false
+	for _, inputFile := range opts.inputFiles {
+		src, err := os.ReadFile(inputFile)
+		if err != nil {
+			fatalf("%v", err)
+		}
+
+		formatted, err := lxn.Format(inputFile, src)
+		if err != nil {
+			printErr(err)
+			failed = true
+			continue
+		}
+
+		if opts.diff && !bytes.Equal(src, formatted) {
+			writeDiff(os.Stdout, inputFile, string(src), string(formatted))
+		}
+		if opts.write && !bytes.Equal(src, formatted) {
+			if err := writeOutput(inputFile, formatted); err != nil {
+				fatalErr(err)
+			}
+		}
+		if !opts.diff && !opts.write {
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// writeDiff writes a unified diff of two versions of a file.
+func writeDiff(w io.Writer, filename string, a, b string) {
+	const context = 3
+
+	x, y := splitLines(a), splitLines(b)
+	edits := diffLines(x, y)
+
+	fmt.Fprintf(w, "--- %s.orig\n", filename)
+	fmt.Fprintf(w, "+++ %s\n", filename)
+	for start := 0; start < len(edits); {
+		// Find the next change and the end of its hunk, which spans all changes
+		// that are at most 2*context lines apart.
+		for start < len(edits) && edits[start].op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+		end, unchanged := start, 0
+		for i := start; i < len(edits) && unchanged <= 2*context; i++ {
+			if edits[i].op == ' ' {
+				unchanged++
+			} else {
+				end, unchanged = i+1, 0
+			}
+		}
+		from, to := max(start-context, 0), min(end+context, len(edits))
+
+		xline, yline := edits[from].x+1, edits[from].y+1
+		xn, yn := 0, 0
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				xn++
+			}
+			if e.op != '-' {
+				yn++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", xline, xn, yline, yn)
+		for _, e := range edits[from:to] {
+			fmt.Fprintf(w, "%c%s\n", e.op, e.line)
+		}
+		start = to
+	}
+}
+
+func splitLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineEdit is a single line of a diff. The operation is either ' ' for an
+// unchanged line, '-' for a deleted line, or '+' for an inserted line. x and y
+// hold the indices of the line in the original and the new version.
+type lineEdit struct {
+	op   byte
+	line string
+	x, y int
+}
+
+// diffLines computes the edits from x to y based on the longest common
+// subsequence of the lines.
+func diffLines(x, y []string) []lineEdit {
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]lineEdit, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			edits = append(edits, lineEdit{op: ' ', line: x[i], x: i, y: j})
+			i++
+			j++
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, lineEdit{op: '-', line: x[i], x: i, y: j})
+			i++
+		default:
+			edits = append(edits, lineEdit{op: '+', line: y[j], x: i, y: j})
+			j++
+		}
+	}
+	return edits
+}
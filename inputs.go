@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/glob"
+)
+
+// expandInputs expands the input arguments of a command into translation files.
+// An argument is either a translation file, a directory, which is searched
+// recursively for translation files, or a glob pattern, which may contain '**'
+// to match any number of directories. The files found in a directory or by a
+// pattern are filtered with the include and exclude patterns and sorted by
+// their path. Each file is returned only once.
+func expandInputs(inputs []string, include []string, exclude []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]struct{})
+	add := func(filename string) {
+		if _, has := seen[filename]; !has {
+			seen[filename] = struct{}{}
+			files = append(files, filename)
+		}
+	}
+
+	for _, input := range inputs {
+		pattern := path.Clean(filepath.ToSlash(input))
+		if glob.HasMeta(pattern) {
+			matches, err := findSourceFiles(glob.Base(pattern), pattern, include, exclude)
+			switch {
+			case err != nil:
+				return nil, err
+			case len(matches) == 0:
+				warner{}.Warn("no translation files match " + input)
+			}
+			for _, filename := range matches {
+				add(filename)
+			}
+			continue
+		}
+
+		if fi, err := os.Stat(input); err != nil || !fi.IsDir() {
+			add(input) // errors are reported when the file is read
+			continue
+		}
+		matches, err := findSourceFiles(pattern, "", include, exclude)
+		switch {
+		case err != nil:
+			return nil, err
+		case len(matches) == 0:
+			warner{}.Warn("directory " + input + " contains no translation files")
+		}
+		for _, filename := range matches {
+			add(filename)
+		}
+	}
+	return files, nil
+}
+
+// findSourceFiles walks the directory and returns the sorted translation files,
+// which match the pattern (if any) and the include and exclude patterns. The
+// include and exclude patterns are matched against the path relative to the
+// directory, or against the file name if they do not contain a slash.
+//
+// Files and directories which are removed while walking are skipped, e.g. when
+// an editor replaces a file while the inputs are watched.
+func findSourceFiles(dir string, pattern string, include []string, exclude []string) ([]string, error) {
+	var files []string
+	root := filepath.FromSlash(dir)
+	err := filepath.WalkDir(root, func(filename string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil && errors.Is(err, fs.ErrNotExist):
+			return nil // a pattern whose base directory does not exist matches nothing
+		case err != nil || d.IsDir() || filepath.Ext(filename) != sourceExt:
+			return err
+		}
+
+		name := filepath.ToSlash(filename)
+		if pattern != "" {
+			if ok, err := matchPattern(pattern, name); !ok || err != nil {
+				return err
+			}
+		}
+		rel, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if len(include) != 0 {
+			if ok, err := matchAny(include, rel); !ok || err != nil {
+				return err
+			}
+		}
+		if ok, err := matchAny(exclude, rel); ok || err != nil {
+			return err
+		}
+		files = append(files, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	for i := range files {
+		files[i] = filepath.FromSlash(files[i])
+	}
+	return files, nil
+}
+
+func matchAny(patterns []string, rel string) (bool, error) {
+	for _, pattern := range patterns {
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if ok, err := matchPattern(pattern, name); ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func matchPattern(pattern string, name string) (bool, error) {
+	ok, err := glob.Match(pattern, name)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	return ok, nil
+}
+
+// splitPatterns splits a comma-separated list of patterns.
+func splitPatterns(patterns string) []string {
+	var res []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			res = append(res, filepath.ToSlash(pattern))
+		}
+	}
+	return res
+}
@@ -9,8 +9,10 @@ import (
 
 // Data contains all the relevant CLDR data that is read from the CLDR repository.
 type Data struct {
-	Identities       map[string]Identity // locale => identity
-	Numbers          map[string]Numbers  // locale => numbers
+	Identities       map[string]Identity     // locale => identity
+	Numbers          map[string]Numbers      // locale => numbers
+	DisplayNames     map[string]DisplayNames // locale => display names
+	Layouts          map[string]Layout       // locale => layout
 	NumberingSystems NumberingSystems
 	Plurals          Plurals
 	Regions          Regions
@@ -21,8 +23,10 @@ type Data struct {
 // Decode decodes the data from filetree that contains the CLDR data.
 func Decode(f filetree.FileTree) (*Data, error) {
 	data := &Data{
-		Identities: make(map[string]Identity),
-		Numbers:    make(map[string]Numbers),
+		Identities:   make(map[string]Identity),
+		Numbers:      make(map[string]Numbers),
+		DisplayNames: make(map[string]DisplayNames),
+		Layouts:      make(map[string]Layout),
 	}
 
 	dirs := [...]string{
@@ -40,6 +44,38 @@ func Decode(f filetree.FileTree) (*Data, error) {
 	return data, nil
 }
 
+// DisplayName returns the display name with the given key for id. The names are
+// looked up in the given locale and all of its parent locales. If no name could be
+// found, an empty string will be returned.
+func (data *Data) DisplayName(id Identity, key func(DisplayNames) string) string {
+	for {
+		if names, has := data.DisplayNames[id.String()]; has {
+			if name := key(names); name != "" {
+				return name
+			}
+		}
+		if id.IsRoot() {
+			return ""
+		}
+		id = data.ParentIdentity(id)
+	}
+}
+
+// CharacterOrder returns the character order for id, which is either "left-to-right"
+// or "right-to-left". The order is inherited from the parent locales. If no order is
+// defined, "left-to-right" will be returned.
+func (data *Data) CharacterOrder(id Identity) string {
+	for {
+		if layout, has := data.Layouts[id.String()]; has && layout.CharacterOrder != "" {
+			return layout.CharacterOrder
+		}
+		if id.IsRoot() {
+			return "left-to-right"
+		}
+		id = data.ParentIdentity(id)
+	}
+}
+
 // ParentIdentity returns the parent identity of id. The parent is determined by the <parentLocale>
 // data. If this data does not define any parent relationship, id will be truncated.
 func (data *Data) ParentIdentity(id Identity) Identity {
@@ -84,17 +120,29 @@ func (data *Data) decode(d *xmlDecoder, root xml.StartElement) {
 func (data *Data) decodeLDML(d *xmlDecoder, _ xml.StartElement) {
 	var identity Identity
 	var numbers Numbers
+	var names DisplayNames
+	var layout Layout
 	d.DecodeElems(decoders{
-		"identity": identity.decode,
-		"numbers":  numbers.decode,
+		"identity":           identity.decode,
+		"layout":             layout.decode,
+		"localeDisplayNames": names.decode,
+		"numbers":            numbers.decode,
 	})
 
+	names.Currencies, numbers.currencyNames = numbers.currencyNames, nil
+
 	if !identity.empty() {
 		loc := identity.String()
 		data.Identities[loc] = identity
 		if !numbers.empty() {
 			data.Numbers[loc] = numbers
 		}
+		if !names.empty() {
+			data.DisplayNames[loc] = names
+		}
+		if !layout.empty() {
+			data.Layouts[loc] = layout
+		}
 	}
 }
 
@@ -15,8 +15,23 @@ func TestDecodeData(t *testing.T) {
 				<identity>
 					<language type="de"/>
 				</identity>
+				<layout>
+					<orientation>
+						<characterOrder>left-to-right</characterOrder>
+					</orientation>
+				</layout>
+				<localeDisplayNames>
+					<languages>
+						<language type="de">Deutsch</language>
+					</languages>
+				</localeDisplayNames>
 				<numbers>
 					<defaultNumberingSystem>latn</defaultNumberingSystem>
+					<currencies>
+						<currency type="EUR">
+							<displayName>Euro</displayName>
+						</currency>
+					</currencies>
 				</numbers>
 			</ldml>
 		`,
@@ -66,6 +81,12 @@ func TestDecodeData(t *testing.T) {
 		t.Errorf("unexpected number of identities: %d", len(data.Identities))
 	case len(data.Numbers) != 1:
 		t.Errorf("unexpected number of numbers: %d", len(data.Numbers))
+	case len(data.DisplayNames) != 1:
+		t.Errorf("unexpected number of display names: %d", len(data.DisplayNames))
+	case data.DisplayNames["de"].Currencies["EUR"] != "Euro":
+		t.Errorf("unexpected currency names: %v", data.DisplayNames["de"].Currencies)
+	case data.Layouts["de"].CharacterOrder != "left-to-right":
+		t.Errorf("unexpected layout: %+v", data.Layouts["de"])
 	case len(data.NumberingSystems) != 1:
 		t.Errorf("unexpected number of numbering systems: %d", len(data.NumberingSystems))
 	case len(data.Plurals.Cardinal) != 1:
@@ -101,6 +122,66 @@ func TestDataParentIdentity(t *testing.T) {
 	}
 }
 
+func TestDataDisplayName(t *testing.T) {
+	data := &Data{
+		Identities: map[string]Identity{
+			"root":         {Language: "root"},
+			"parent":       {Language: "parent"},
+			"parent-child": {Language: "parent", Territory: "child"},
+		},
+		DisplayNames: map[string]DisplayNames{
+			"root": {
+				LocalePattern: "{0} ({1})",
+			},
+			"parent": {
+				Languages: map[string]string{"lang": "parent-lang"},
+			},
+			"parent-child": {
+				Territories: map[string]string{"terr": "child-terr"},
+			},
+		},
+	}
+
+	lang := func(n DisplayNames) string { return n.Languages["lang"] }
+	terr := func(n DisplayNames) string { return n.Territories["terr"] }
+	pattern := func(n DisplayNames) string { return n.LocalePattern }
+
+	child := data.Identities["parent-child"]
+	if name := data.DisplayName(child, lang); name != "parent-lang" {
+		t.Errorf("unexpected language name for the child locale: %q", name)
+	}
+	if name := data.DisplayName(child, terr); name != "child-terr" {
+		t.Errorf("unexpected territory name for the child locale: %q", name)
+	}
+	if name := data.DisplayName(child, pattern); name != "{0} ({1})" {
+		t.Errorf("unexpected locale pattern for the child locale: %q", name)
+	}
+	if name := data.DisplayName(data.Identities["parent"], terr); name != "" {
+		t.Errorf("unexpected territory name for the parent locale: %q", name)
+	}
+}
+
+func TestDataCharacterOrder(t *testing.T) {
+	data := &Data{
+		Identities: map[string]Identity{
+			"root":         {Language: "root"},
+			"parent":       {Language: "parent"},
+			"parent-child": {Language: "parent", Territory: "child"},
+			"other":        {Language: "other"},
+		},
+		Layouts: map[string]Layout{
+			"parent": {CharacterOrder: "right-to-left"},
+		},
+	}
+
+	if order := data.CharacterOrder(data.Identities["parent-child"]); order != "right-to-left" {
+		t.Errorf("unexpected character order for the child locale: %q", order)
+	}
+	if order := data.CharacterOrder(data.Identities["other"]); order != "left-to-right" {
+		t.Errorf("unexpected character order for the other locale: %q", order)
+	}
+}
+
 func TestDataDefaultNumberingSystem(t *testing.T) {
 	data := &Data{
 		Identities: map[string]Identity{
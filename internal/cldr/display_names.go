@@ -0,0 +1,80 @@
+package cldr
+
+import "encoding/xml"
+
+// DisplayNames holds the localized names for languages, scripts, territories, and
+// currencies of a single locale. The locale pattern and the locale separator are
+// used to compose the display name of a locale from the names of its subtags.
+type DisplayNames struct {
+	Languages       map[string]string // language code => display name
+	Scripts         map[string]string // script code => display name
+	Territories     map[string]string // territory code => display name
+	Currencies      map[string]string // currency code => display name
+	LocalePattern   string            // e.g. "{0} ({1})"
+	LocaleSeparator string            // e.g. "{0}, {1}"
+}
+
+func (n *DisplayNames) empty() bool {
+	return len(n.Languages) == 0 &&
+		len(n.Scripts) == 0 &&
+		len(n.Territories) == 0 &&
+		len(n.Currencies) == 0 &&
+		n.LocalePattern == "" &&
+		n.LocaleSeparator == ""
+}
+
+func (n *DisplayNames) decode(d *xmlDecoder, _ xml.StartElement) {
+	n.Languages = make(map[string]string)
+	n.Scripts = make(map[string]string)
+	n.Territories = make(map[string]string)
+
+	nameDecoder := func(elemName string, names map[string]string, normalize func(string) string) decodeFunc {
+		return func(d *xmlDecoder, _ xml.StartElement) {
+			d.DecodeElem(elemName, func(d *xmlDecoder, elem xml.StartElement) {
+				// Alternative names (e.g. short or variant) are not supported.
+				if code := xmlAttrib(elem, "type"); code != "" && xmlAttrib(elem, "alt") == "" {
+					names[normalize(code)] = d.ReadString(elem)
+				}
+				d.SkipElem()
+			})
+		}
+	}
+
+	noop := func(code string) string { return code }
+
+	d.DecodeElems(decoders{
+		"languages":   nameDecoder("language", n.Languages, normalizeTag),
+		"scripts":     nameDecoder("script", n.Scripts, noop),
+		"territories": nameDecoder("territory", n.Territories, noop),
+		"localeDisplayPattern": func(d *xmlDecoder, _ xml.StartElement) {
+			d.DecodeElems(decoders{
+				"localePattern": func(d *xmlDecoder, elem xml.StartElement) {
+					n.LocalePattern = d.ReadString(elem)
+					d.SkipElem()
+				},
+				"localeSeparator": func(d *xmlDecoder, elem xml.StartElement) {
+					n.LocaleSeparator = d.ReadString(elem)
+					d.SkipElem()
+				},
+			})
+		},
+	})
+}
+
+// CurrencyNames holds the localized display names of currencies.
+type CurrencyNames map[string]string // currency code => display name
+
+func (c *CurrencyNames) decode(d *xmlDecoder, _ xml.StartElement) {
+	*c = make(CurrencyNames)
+
+	d.DecodeElem("currency", func(d *xmlDecoder, elem xml.StartElement) {
+		code := xmlAttrib(elem, "type")
+		d.DecodeElem("displayName", func(d *xmlDecoder, elem xml.StartElement) {
+			// Plural forms of the display name are not supported.
+			if code != "" && xmlAttrib(elem, "count") == "" && xmlAttrib(elem, "alt") == "" {
+				(*c)[code] = d.ReadString(elem)
+			}
+			d.SkipElem()
+		})
+	})
+}
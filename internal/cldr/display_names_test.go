@@ -0,0 +1,107 @@
+package cldr
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDisplayNamesDecode(t *testing.T) {
+	const xmlData = `<?xml version="1.0" encoding="UTF-8" ?>
+	<root>
+		<localeDisplayNames>
+			<localeDisplayPattern>
+				<localePattern>{0} ({1})</localePattern>
+				<localeSeparator>{0}, {1}</localeSeparator>
+				<localeKeyTypePattern>{0}: {1}</localeKeyTypePattern>
+			</localeDisplayPattern>
+			<languages>
+				<language type="de">Deutsch</language>
+				<language type="de_AT">Österreichisches Deutsch</language>
+				<language type="en">Englisch</language>
+				<language type="en_GB" alt="short">Englisch (GB)</language>
+			</languages>
+			<scripts>
+				<script type="Latn">Lateinisch</script>
+			</scripts>
+			<territories>
+				<territory type="001">Welt</territory>
+				<territory type="AT">Österreich</territory>
+				<territory type="GB" alt="short">UK</territory>
+			</territories>
+			<keys>
+				<key type="calendar">Kalender</key>
+			</keys>
+		</localeDisplayNames>
+	</root>
+	`
+
+	var names DisplayNames
+	err := decodeXML("test", strings.NewReader(xmlData), func(d *xmlDecoder, _ xml.StartElement) {
+		d.DecodeElem("localeDisplayNames", names.decode)
+	})
+
+	expected := DisplayNames{
+		Languages: map[string]string{
+			"de":    "Deutsch",
+			"de-AT": "Österreichisches Deutsch",
+			"en":    "Englisch",
+		},
+		Scripts: map[string]string{
+			"Latn": "Lateinisch",
+		},
+		Territories: map[string]string{
+			"001": "Welt",
+			"AT":  "Österreich",
+		},
+		LocalePattern:   "{0} ({1})",
+		LocaleSeparator: "{0}, {1}",
+	}
+
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(names, expected):
+		t.Errorf("unexpected display names: %#v", names)
+	}
+}
+
+func TestCurrencyNamesDecode(t *testing.T) {
+	const xmlData = `<?xml version="1.0" encoding="UTF-8" ?>
+	<root>
+		<currencies>
+			<currency type="EUR">
+				<displayName>Euro</displayName>
+				<displayName count="one">Euro</displayName>
+				<displayName count="other">Euro</displayName>
+				<symbol>€</symbol>
+			</currency>
+			<currency type="USD">
+				<displayName>US-Dollar</displayName>
+				<symbol>$</symbol>
+			</currency>
+			<currency type="XXX">
+				<symbol>¤</symbol>
+			</currency>
+		</currencies>
+	</root>
+	`
+
+	var names CurrencyNames
+	err := decodeXML("test", strings.NewReader(xmlData), func(d *xmlDecoder, _ xml.StartElement) {
+		d.DecodeElem("currencies", names.decode)
+	})
+
+	expected := CurrencyNames{
+		"EUR": "Euro",
+		"USD": "US-Dollar",
+	}
+
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(names, expected):
+		t.Errorf("unexpected currency names: %#v", names)
+	}
+}
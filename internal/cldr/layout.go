@@ -0,0 +1,21 @@
+package cldr
+
+import "encoding/xml"
+
+// Layout holds the layout information of a single locale.
+type Layout struct {
+	CharacterOrder string // "left-to-right" or "right-to-left"
+}
+
+func (l *Layout) empty() bool {
+	return l.CharacterOrder == ""
+}
+
+func (l *Layout) decode(d *xmlDecoder, _ xml.StartElement) {
+	d.DecodeElem("orientation", func(d *xmlDecoder, _ xml.StartElement) {
+		d.DecodeElem("characterOrder", func(d *xmlDecoder, elem xml.StartElement) {
+			l.CharacterOrder = d.ReadString(elem)
+			d.SkipElem()
+		})
+	})
+}
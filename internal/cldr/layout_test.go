@@ -0,0 +1,32 @@
+package cldr
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestLayoutDecode(t *testing.T) {
+	const xmlData = `<?xml version="1.0" encoding="UTF-8" ?>
+	<root>
+		<layout>
+			<orientation>
+				<characterOrder>right-to-left</characterOrder>
+				<lineOrder>top-to-bottom</lineOrder>
+			</orientation>
+		</layout>
+	</root>
+	`
+
+	var layout Layout
+	err := decodeXML("test", strings.NewReader(xmlData), func(d *xmlDecoder, _ xml.StartElement) {
+		d.DecodeElem("layout", layout.decode)
+	})
+
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case layout.CharacterOrder != "right-to-left":
+		t.Errorf("unexpected character order: %q", layout.CharacterOrder)
+	}
+}
@@ -26,6 +26,8 @@ type Numbers struct {
 	ScientificFormats map[string]NumberFormat  // numbering system => number format
 	PercentFormats    map[string]NumberFormat  // numbering system => number format
 	CurrencyFormats   map[string]NumberFormat  // numbering system => number format
+
+	currencyNames CurrencyNames // moved to the display names after decoding
 }
 
 func (n *Numbers) empty() bool {
@@ -81,6 +83,7 @@ func (n *Numbers) decode(d *xmlDecoder, _ xml.StartElement) {
 		"scientificFormats": formatDecoder(n.ScientificFormats),
 		"percentFormats":    formatDecoder(n.PercentFormats),
 		"currencyFormats":   formatDecoder(n.CurrencyFormats),
+		"currencies":        n.currencyNames.decode,
 	})
 
 	// normalize
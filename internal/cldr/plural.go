@@ -27,6 +27,7 @@ const (
 type Plurals struct {
 	Cardinal []PluralRules
 	Ordinal  []PluralRules
+	Ranges   []PluralRanges
 }
 
 func (p *Plurals) decode(d *xmlDecoder, elem xml.StartElement) {
@@ -45,6 +46,13 @@ func (p *Plurals) decode(d *xmlDecoder, elem xml.StartElement) {
 			p.Ordinal[len(p.Ordinal)-1].decode(d, elem)
 		})
 
+	case "":
+		// The plural ranges are defined in a <plurals> element without any type.
+		d.DecodeElem("pluralRanges", func(d *xmlDecoder, elem xml.StartElement) {
+			p.Ranges = append(p.Ranges, PluralRanges{})
+			p.Ranges[len(p.Ranges)-1].decode(d, elem)
+		})
+
 	default:
 		d.SkipElem()
 	}
@@ -79,6 +87,35 @@ func (p *PluralRules) decode(d *xmlDecoder, elem xml.StartElement) {
 	})
 }
 
+// PluralRanges holds the plural ranges for a group of locales.
+type PluralRanges struct {
+	Locales []string
+	Ranges  []PluralRange
+}
+
+func (p *PluralRanges) decode(d *xmlDecoder, elem xml.StartElement) {
+	if locales := xmlAttrib(elem, "locales"); locales != "" {
+		p.Locales = strings.Split(locales, " ")
+	}
+
+	d.DecodeElem("pluralRange", func(d *xmlDecoder, elem xml.StartElement) {
+		p.Ranges = append(p.Ranges, PluralRange{
+			Start:  xmlAttrib(elem, "start"),
+			End:    xmlAttrib(elem, "end"),
+			Result: xmlAttrib(elem, "result"),
+		})
+		d.SkipElem()
+	})
+}
+
+// PluralRange defines the plural category of a number range, where the start and
+// the end of the range have the given plural categories.
+type PluralRange struct {
+	Start  string // plural category of the range start
+	End    string // plural category of the range end
+	Result string // plural category of the range
+}
+
 // Operand represents an operand in a plural rule.
 type Operand rune
 
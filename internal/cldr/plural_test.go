@@ -38,6 +38,50 @@ func TestPluralsDecode(t *testing.T) {
 	}
 }
 
+func TestPluralRangesDecode(t *testing.T) {
+	const xmlData = `<?xml version="1.0" encoding="UTF-8" ?>
+	<root>
+		<plurals>
+			<pluralRanges locales="de en">
+				<pluralRange start="one" end="other" result="other"/>
+				<pluralRange start="other" end="one" result="one"/>
+			</pluralRanges>
+			<pluralRanges locales="ja">
+				<pluralRange start="other" end="other" result="other"/>
+			</pluralRanges>
+		</plurals>
+	</root>
+	`
+
+	var plurals Plurals
+	err := decodeXML("test", strings.NewReader(xmlData), func(d *xmlDecoder, _ xml.StartElement) {
+		d.DecodeElem("plurals", plurals.decode)
+	})
+
+	expected := []PluralRanges{
+		{
+			Locales: []string{"de", "en"},
+			Ranges: []PluralRange{
+				{Start: One, End: Other, Result: Other},
+				{Start: Other, End: One, Result: One},
+			},
+		},
+		{
+			Locales: []string{"ja"},
+			Ranges: []PluralRange{
+				{Start: Other, End: Other, Result: Other},
+			},
+		},
+	}
+
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !reflect.DeepEqual(plurals.Ranges, expected):
+		t.Errorf("unexpected plural ranges: %+v", plurals.Ranges)
+	}
+}
+
 func TestPluralRulesDecode(t *testing.T) {
 	const xmlData = `<?xml version="1.0" encoding="UTF-8" ?>
 	<root>
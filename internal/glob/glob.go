@@ -0,0 +1,87 @@
+// Package glob matches slash-separated paths against glob patterns, which
+// support '**' to match any number of directories.
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether name matches the pattern. Both are slash-separated
+// paths. Each element of the pattern is matched against an element of the name
+// as in path.Match, and an element '**' matches zero or more elements. The only
+// possible error is path.ErrBadPattern.
+func Match(pattern string, name string) (bool, error) {
+	return match(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func match(pattern []string, name []string) (bool, error) {
+	for len(pattern) != 0 {
+		if pattern[0] == "**" {
+			// Skip consecutive '**' elements, which match the same.
+			for len(pattern) != 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true, nil
+			}
+			for i := range name {
+				if ok, err := match(pattern, name[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, validate(pattern)
+		}
+
+		if len(name) == 0 {
+			return false, validate(pattern)
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if !ok || err != nil {
+			if err == nil {
+				err = validate(pattern[1:])
+			}
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// validate checks the remaining pattern elements for syntax errors, so a bad
+// pattern is reported regardless of the name.
+func validate(pattern []string) error {
+	for _, elem := range pattern {
+		if _, err := path.Match(elem, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasMeta reports whether the path contains any of the magic characters
+// recognized by Match.
+func HasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[\`)
+}
+
+// Base returns the leading elements of the pattern which do not contain any
+// magic characters, i.e. the directory where the matching files are located.
+// If the first element already contains magic characters, "." is returned.
+func Base(pattern string) string {
+	elems := strings.Split(pattern, "/")
+	n := 0
+	for n < len(elems) && !HasMeta(elems[n]) {
+		n++
+	}
+	switch {
+	case n == len(elems):
+		return pattern
+	case n == 0:
+		return "."
+	case n == 1 && elems[0] == "":
+		return "/"
+	default:
+		return strings.Join(elems[:n], "/")
+	}
+}
@@ -0,0 +1,60 @@
+package glob
+
+import (
+	"path"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{pattern: "*.lxn", name: "a.lxn", match: true},
+		{pattern: "*.lxn", name: "dir/a.lxn", match: false},
+		{pattern: "dir/*.lxn", name: "dir/a.lxn", match: true},
+		{pattern: "**/*.lxn", name: "a.lxn", match: true},
+		{pattern: "**/*.lxn", name: "dir/sub/a.lxn", match: true},
+		{pattern: "**/*.lxn", name: "dir/sub/a.txt", match: false},
+		{pattern: "dir/**", name: "dir/a.lxn", match: true},
+		{pattern: "dir/**", name: "dir/sub/a.lxn", match: true},
+		{pattern: "dir/**", name: "other/a.lxn", match: false},
+		{pattern: "dir/**/**/a.lxn", name: "dir/a.lxn", match: true},
+		{pattern: "dir/**/sub/*.lxn", name: "dir/x/y/sub/a.lxn", match: true},
+		{pattern: "dir/**/sub/*.lxn", name: "dir/x/y/a.lxn", match: false},
+		{pattern: "de-??/*.lxn", name: "de-AT/a.lxn", match: true},
+		{pattern: "[a-c]/*.lxn", name: "d/a.lxn", match: false},
+	}
+
+	for _, test := range tests {
+		ok, err := Match(test.pattern, test.name)
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error for %q: %v", test.pattern, err)
+		case ok != test.match:
+			t.Errorf("unexpected match result for %q and %q: %v", test.pattern, test.name, ok)
+		}
+	}
+
+	if _, err := Match("**/[a-", "x/y"); err != path.ErrBadPattern {
+		t.Errorf("unexpected error for bad pattern: %v", err)
+	}
+}
+
+func TestBase(t *testing.T) {
+	tests := map[string]string{
+		"dir/sub/*.lxn":  "dir/sub",
+		"dir/**/*.lxn":   "dir",
+		"**/*.lxn":       ".",
+		"/abs/**":        "/abs",
+		"/*.lxn":         "/",
+		"dir/a.lxn":      "dir/a.lxn",
+		"dir/de-??/**/x": "dir",
+	}
+	for pattern, expected := range tests {
+		if base := Base(pattern); base != expected {
+			t.Errorf("unexpected base for %q: %s", pattern, base)
+		}
+	}
+}
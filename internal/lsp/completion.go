@@ -0,0 +1,133 @@
+package lsp
+
+import "strings"
+
+type completion struct {
+	name   string
+	detail string
+}
+
+var replacementTypes = []completion{
+	{"string", "string value (default)"},
+	{"number", "formatted number"},
+	{"percent", "formatted percentage"},
+	{"money", "formatted amount of money"},
+	{"plural", "plural variants for a number"},
+	{"pluralrange", "plural variants for a number range"},
+	{"select", "variants for a set of values"},
+	{"tag", "markup tag"},
+}
+
+var pluralCategories = []completion{
+	{"zero", "plural category"},
+	{"one", "plural category"},
+	{"two", "plural category"},
+	{"few", "plural category"},
+	{"many", "plural category"},
+	{"other", "plural category (required)"},
+}
+
+var replacementOptions = map[string][]completion{
+	"plural": append([]completion{
+		{"cardinal", "cardinal plural rules (default)"},
+		{"ordinal", "ordinal plural rules"},
+		{"offset", "offset which is subtracted from the number"},
+	}, pluralCategories...),
+	"pluralrange": append([]completion{
+		{"to", "end of the range (required)"},
+	}, pluralCategories...),
+	"money": {
+		{"currency", "currency code (required)"},
+	},
+	"select": {
+		{"default", "value which is used for unknown values"},
+	},
+}
+
+// completion returns the completion items for the given offset. Replacement
+// types are completed after the colon of a replacement, and the options of a
+// replacement are completed after a dot.
+func (s *Server) completion(doc *document, offset int) any {
+	items := []CompletionItem{}
+	switch typ, isOption, ok := completionContext(doc.text, offset); {
+	case !ok:
+	case isOption:
+		for _, c := range replacementOptions[typ] {
+			items = append(items, CompletionItem{Label: c.name, Kind: completionProperty, Detail: c.detail})
+		}
+	default:
+		for _, c := range replacementTypes {
+			items = append(items, CompletionItem{Label: c.name, Kind: completionKeyword, Detail: c.detail})
+		}
+	}
+	return CompletionList{Items: items}
+}
+
+// completionContext determines the replacement which encloses the offset. If the
+// offset is in the type of the replacement, isOption is false. If the offset is
+// in the name of an option, the replacement type and true are returned.
+func completionContext(text string, offset int) (typ string, isOption bool, ok bool) {
+	start, depth := -1, 0
+	for i := offset - 1; i >= 0 && start < 0; i-- {
+		switch text[i] {
+		case '}':
+			depth++
+		case '{':
+			switch {
+			case depth > 0:
+				depth--
+			case i > 0 && text[i-1] == '$':
+				start = i - 1
+			default:
+				return "", false, false // in the value of an option
+			}
+		case '\n':
+			if i+1 < offset && text[i+1] != ' ' && text[i+1] != '\t' {
+				return "", false, false // start of a new declaration
+			}
+		}
+	}
+	if start < 0 {
+		return "", false, false
+	}
+
+	head := text[start+2 : offset]
+	key := head[:identLen(head)]
+	rest := head[len(key):]
+	if key == "" || !strings.HasPrefix(rest, ":") {
+		return "", false, false
+	}
+	typ = rest[1 : 1+identLen(rest[1:])]
+	rest = rest[1+len(typ):]
+	if rest == "" {
+		return "", false, true
+	}
+
+	rest = rest[:len(rest)-trailingIdentLen(rest)]
+	if !strings.HasSuffix(rest, ".") {
+		return "", false, false
+	}
+	return strings.ToLower(typ), true, true
+}
+
+func identLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func trailingIdentLen(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if !isIdentChar(s[i]) {
+			return len(s) - 1 - i
+		}
+	}
+	return len(s)
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '-' || ('0' <= ch && ch <= '9') || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ch >= 0x80
+}
@@ -0,0 +1,147 @@
+package lsp
+
+import (
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// document is an open text document. The syntax tree is updated whenever the
+// text changes.
+type document struct {
+	uri        string
+	filename   string
+	text       string
+	lineStarts []int // byte offsets of the line starts
+	file       *lxn.File
+}
+
+func newDocument(uri string, text string) *document {
+	doc := &document{
+		uri:      uri,
+		filename: uriToPath(uri),
+	}
+	doc.update(text)
+	return doc
+}
+
+func (d *document) update(text string) {
+	d.text = text
+	d.lineStarts = append(d.lineStarts[:0], 0)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+	d.file, _ = lxn.ParseFile(d.filename, []byte(text))
+}
+
+// position converts a byte offset into a protocol position.
+func (d *document) position(offset int) Position {
+	offset = max(0, min(offset, len(d.text)))
+	line := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > offset }) - 1
+	return Position{
+		Line:      line,
+		Character: utf16Len(d.text[d.lineStarts[line]:offset]),
+	}
+}
+
+// offset converts a protocol position into a byte offset.
+func (d *document) offset(pos Position) int {
+	switch {
+	case pos.Line < 0:
+		return 0
+	case pos.Line >= len(d.lineStarts):
+		return len(d.text)
+	}
+
+	offset := d.lineStarts[pos.Line]
+	for n := 0; n < pos.Character && offset < len(d.text) && d.text[offset] != '\n'; {
+		ch, size := utf8.DecodeRuneInString(d.text[offset:])
+		n += utf16RuneLen(ch)
+		offset += size
+	}
+	return offset
+}
+
+func (d *document) nodeRange(n lxn.Node) Range {
+	return Range{Start: d.position(n.Start().Offset), End: d.position(n.End().Offset)}
+}
+
+// replacementAt returns the innermost replacement which contains the offset.
+func (d *document) replacementAt(offset int) *lxn.ReplacementExpr {
+	var find func(fragments []lxn.Fragment) *lxn.ReplacementExpr
+	find = func(fragments []lxn.Fragment) *lxn.ReplacementExpr {
+		for _, fragment := range fragments {
+			expr, ok := fragment.(*lxn.ReplacementExpr)
+			if !ok || offset < expr.Start().Offset || offset >= expr.End().Offset {
+				continue
+			}
+			for _, opt := range expr.Options {
+				if inner := find(opt.Value); inner != nil {
+					return inner
+				}
+			}
+			return expr
+		}
+		return nil
+	}
+
+	for _, decl := range d.file.Decls {
+		if msg, ok := decl.(*lxn.MessageDecl); ok {
+			if expr := find(msg.Body); expr != nil {
+				return expr
+			}
+		}
+	}
+	return nil
+}
+
+// sectionAt returns the section which is active at the given offset.
+func (d *document) sectionAt(offset int) string {
+	section := ""
+	for _, decl := range d.file.Decls {
+		if decl.Start().Offset > offset {
+			break
+		}
+		if sec, ok := decl.(*lxn.SectionDecl); ok {
+			section = sec.Name
+		}
+	}
+	return section
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, ch := range s {
+		n += utf16RuneLen(ch)
+	}
+	return n
+}
+
+func utf16RuneLen(ch rune) int {
+	if ch >= 0x10000 {
+		return 2 // surrogate pair
+	}
+	return 1
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathToURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,108 @@
+package lsp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/liblxn/lxnc/locale"
+	"github.com/liblxn/lxnc/lxn"
+)
+
+var pluralCategoryNames = [...]string{
+	locale.Zero:  "zero",
+	locale.One:   "one",
+	locale.Two:   "two",
+	locale.Few:   "few",
+	locale.Many:  "many",
+	locale.Other: "other",
+}
+
+var operandNames = [...]string{
+	locale.AbsoluteValue:       "n",
+	locale.IntegerDigits:       "i",
+	locale.NumFracDigit:        "v",
+	locale.NumFracDigitNoZeros: "w",
+	locale.FracDigits:          "f",
+	locale.FracDigitsNoZeros:   "t",
+	locale.CompactDecimalExp:   "e",
+}
+
+// hover shows the plural rules of the document's locale for plural and plural
+// range replacements.
+func (s *Server) hover(doc *document, offset int) any {
+	expr := doc.replacementAt(offset)
+	if expr == nil {
+		return nil
+	}
+
+	typ := strings.ToLower(expr.Type)
+	if typ != "plural" && typ != "pluralrange" {
+		return nil
+	}
+	loc, ok := s.locale(doc)
+	if !ok {
+		return nil
+	}
+
+	kind, plural := "cardinal", locale.CardinalPlural(loc)
+	if typ == "plural" && hasOption(expr, "ordinal") {
+		kind, plural = "ordinal", locale.OrdinalPlural(loc)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** (%s plural rules for %s)\n\n", typ, kind, loc.String())
+	for _, rules := range plural.Rules() {
+		fmt.Fprintf(&b, "- `%s`: %s\n", pluralCategoryNames[rules.Category()], formatPluralRules(rules))
+	}
+	b.WriteString("- `other`: all other numbers\n")
+
+	rng := doc.nodeRange(expr)
+	return Hover{
+		Contents: MarkupContent{Kind: "markdown", Value: b.String()},
+		Range:    &rng,
+	}
+}
+
+// formatPluralRules formats plural rules in CLDR syntax (e.g. "i = 1 and v = 0").
+func formatPluralRules(rules locale.PluralRules) string {
+	var b strings.Builder
+	rules.Iter(func(r locale.PluralRule) {
+		b.WriteString(operandNames[r.Operand])
+		if r.ModuloExp > 0 {
+			b.WriteString(" % 1" + strings.Repeat("0", r.ModuloExp))
+		}
+		if r.Operator == locale.NotEqual {
+			b.WriteString(" != ")
+		} else {
+			b.WriteString(" = ")
+		}
+		for i := 0; i < r.Ranges.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			rng := r.Ranges.At(i)
+			b.WriteString(strconv.Itoa(rng.LowerBound))
+			if rng.UpperBound != rng.LowerBound {
+				b.WriteString(".." + strconv.Itoa(rng.UpperBound))
+			}
+		}
+
+		switch r.Connective {
+		case locale.Conjunction:
+			b.WriteString(" and ")
+		case locale.Disjunction:
+			b.WriteString(" or ")
+		}
+	})
+	return b.String()
+}
+
+func hasOption(expr *lxn.ReplacementExpr, name string) bool {
+	for _, opt := range expr.Options {
+		if strings.EqualFold(opt.Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+// Package lsp implements a language server for lxn files, which speaks the
+// Language Server Protocol over a reader and a writer (usually stdin and stdout).
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/liblxn/lxnc/locale"
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// Server is a language server for lxn files. It provides diagnostics,
+// completion, hover information, go-to-definition for message references, and
+// document symbols.
+//
+// The locale of a document, which is needed for the plural rules, is taken from
+// the "locale" initialization option. If the option is not set, the name of the
+// document's directory is used (e.g. 'translations/de/messages.lxn').
+type Server struct {
+	// ReadFile reads the files which are included by a document and not opened
+	// in the editor. If ReadFile is nil, os.ReadFile is used.
+	ReadFile func(filename string) ([]byte, error)
+
+	w        io.Writer
+	docs     map[string]*document
+	localeID string
+	shutdown bool
+}
+
+// Serve reads the requests from r and writes the responses and notifications to
+// w until the client sends an exit notification or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	s.docs = make(map[string]*document)
+
+	br := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(br)
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			if rerr, ok := err.(*responseError); ok {
+				if err := s.reply(nil, nil, rerr); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		case msg.Method == "exit":
+			return nil
+		}
+
+		if msg.ID == nil {
+			s.notify(msg)
+			continue
+		}
+		result, rerr := s.handle(msg)
+		if err := s.reply(msg.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) (any, *responseError) {
+	if s.shutdown {
+		return nil, &responseError{Code: invalidRequest, Message: "server is shut down"}
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		s.localeID = params.InitializationOptions.Locale
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       syncFull,
+				CompletionProvider:     &CompletionOptions{TriggerCharacters: []string{":", "."}},
+				HoverProvider:          true,
+				DefinitionProvider:     true,
+				DocumentSymbolProvider: true,
+			},
+			ServerInfo: ServerInfo{Name: "lxnc"},
+		}, nil
+
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/completion":
+		return s.positionRequest(msg, s.completion)
+	case "textDocument/hover":
+		return s.positionRequest(msg, s.hover)
+	case "textDocument/definition":
+		return s.positionRequest(msg, s.definition)
+
+	case "textDocument/documentSymbol":
+		var params struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		doc, has := s.docs[params.TextDocument.URI]
+		if !has {
+			return nil, &responseError{Code: invalidParams, Message: "unknown document: " + params.TextDocument.URI}
+		}
+		return documentSymbols(doc), nil
+
+	default:
+		return nil, &responseError{Code: methodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+func (s *Server) positionRequest(msg *message, fn func(*document, int) any) (any, *responseError) {
+	var params TextDocumentPositionParams
+	if err := unmarshalParams(msg, &params); err != nil {
+		return nil, err
+	}
+	doc, has := s.docs[params.TextDocument.URI]
+	if !has {
+		return nil, &responseError{Code: invalidParams, Message: "unknown document: " + params.TextDocument.URI}
+	}
+	return fn(doc, doc.offset(params.Position)), nil
+}
+
+// notify handles a notification. Errors cannot be reported for notifications,
+// so invalid notifications are ignored.
+func (s *Server) notify(msg *message) {
+	switch msg.Method {
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if unmarshalParams(msg, &params) == nil {
+			doc := newDocument(params.TextDocument.URI, params.TextDocument.Text)
+			s.docs[doc.uri] = doc
+			s.publishDiagnostics(doc)
+		}
+
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if unmarshalParams(msg, &params) == nil {
+			doc, has := s.docs[params.TextDocument.URI]
+			if has && len(params.ContentChanges) != 0 {
+				doc.update(params.ContentChanges[len(params.ContentChanges)-1].Text)
+				s.publishDiagnostics(doc)
+			}
+		}
+
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if unmarshalParams(msg, &params) == nil {
+			delete(s.docs, params.TextDocument.URI)
+			s.send("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []Diagnostic{},
+			})
+		}
+	}
+}
+
+// publishDiagnostics sends the errors of the document. Errors in included files
+// are reported at the include directive in the document, all other errors span
+// the offending text.
+func (s *Server) publishDiagnostics(doc *document) {
+	diagnostics := []Diagnostic{}
+	_, err := lxn.ParseSource(doc.filename, []byte(doc.text), s.readFile)
+	if errs, ok := err.(lxn.ErrorList); ok {
+		for _, e := range errs {
+			e, ok := e.(lxn.Error)
+			if !ok {
+				continue
+			}
+
+			rng := Range{Start: doc.position(e.Pos.Offset), End: doc.position(e.Pos.Offset)}
+			if n := len(e.Includes); n != 0 {
+				pos := doc.position(e.Includes[n-1].Offset)
+				rng = Range{Start: pos, End: pos}
+			} else if e.End.Line != 0 {
+				rng.End = doc.position(e.End.Offset)
+			}
+
+			msg := e.Error()
+			if len(e.Includes) == 0 {
+				// The position is already given by the range.
+				msg = e.Err.Error()
+				if e.Suggestion != "" {
+					msg += " (" + e.Suggestion + ")"
+				}
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    rng,
+				Severity: severityError,
+				Source:   "lxnc",
+				Message:  msg,
+			})
+		}
+	}
+
+	s.send("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// readFile reads a file from the open documents or from disk.
+func (s *Server) readFile(filename string) ([]byte, error) {
+	for _, doc := range s.docs {
+		if filepath.Clean(doc.filename) == filepath.Clean(filename) {
+			return []byte(doc.text), nil
+		}
+	}
+	if s.ReadFile != nil {
+		return s.ReadFile(filename)
+	}
+	return os.ReadFile(filename)
+}
+
+// locale returns the locale of the document. If the locale cannot be determined,
+// false is returned.
+func (s *Server) locale(doc *document) (locale.Locale, bool) {
+	tag := s.localeID
+	if tag == "" {
+		tag = filepath.Base(filepath.Dir(doc.filename))
+	}
+	loc, err := locale.New(tag)
+	return loc, err == nil
+}
+
+func (s *Server) send(method string, params any) {
+	content, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	writeMessage(s.w, &message{Method: method, Params: content})
+}
+
+func (s *Server) reply(id *json.RawMessage, result any, rerr *responseError) error {
+	msg := &message{ID: id, Error: rerr}
+	if id == nil {
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+	if rerr == nil {
+		content, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = content
+	}
+	return writeMessage(s.w, msg)
+}
+
+func unmarshalParams(msg *message, params any) *responseError {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
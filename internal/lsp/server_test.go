@@ -0,0 +1,290 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testClient is an in-process client which talks to a server over pipes.
+type testClient struct {
+	t      *testing.T
+	w      io.WriteCloser
+	r      *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func newTestClient(t *testing.T, server *Server) *testClient {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &testClient{
+		t:    t,
+		w:    clientOut,
+		r:    bufio.NewReader(clientIn),
+		done: make(chan error, 1),
+	}
+	go func() {
+		err := server.Serve(serverIn, serverOut)
+		serverOut.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() {
+		c.w.Close()
+		if err := <-c.done; err != nil {
+			t.Errorf("unexpected server error: %v", err)
+		}
+	})
+	return c
+}
+
+func (c *testClient) write(msg *message) {
+	c.t.Helper()
+	if err := writeMessage(c.w, msg); err != nil {
+		c.t.Fatalf("error writing message: %v", err)
+	}
+}
+
+func (c *testClient) read() *message {
+	c.t.Helper()
+	msg, err := readMessage(c.r)
+	if err != nil {
+		c.t.Fatalf("error reading message: %v", err)
+	}
+	return msg
+}
+
+// call sends a request and decodes the result of the response. Notifications
+// which are received before the response are skipped.
+func (c *testClient) call(method string, params any, result any) {
+	c.t.Helper()
+	c.nextID++
+	id := json.RawMessage(fmt.Sprint(c.nextID))
+	c.write(&message{ID: &id, Method: method, Params: marshal(c.t, params)})
+
+	for {
+		msg := c.read()
+		if msg.ID == nil {
+			continue
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("unexpected error for %s: %v", method, msg.Error)
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("error decoding result for %s: %v", method, err)
+		}
+		return
+	}
+}
+
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+	c.write(&message{Method: method, Params: marshal(c.t, params)})
+}
+
+// diagnostics waits for the next diagnostics notification.
+func (c *testClient) diagnostics() PublishDiagnosticsParams {
+	c.t.Helper()
+	for {
+		msg := c.read()
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			c.t.Fatalf("error decoding diagnostics: %v", err)
+		}
+		return params
+	}
+}
+
+func (c *testClient) open(uri string, text string) PublishDiagnosticsParams {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "lxn", Version: 1, Text: text},
+	})
+	return c.diagnostics()
+}
+
+func marshal(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error encoding params: %v", err)
+	}
+	return content
+}
+
+func newInitializedClient(t *testing.T, files map[string]string) *testClient {
+	server := &Server{
+		ReadFile: func(filename string) ([]byte, error) {
+			if content, has := files[filename]; has {
+				return []byte(content), nil
+			}
+			return nil, fmt.Errorf("file not found: %s", filename)
+		},
+	}
+	c := newTestClient(t, server)
+
+	var result InitializeResult
+	c.call("initialize", map[string]any{"initializationOptions": map[string]string{"locale": "en"}}, &result)
+	if !result.Capabilities.HoverProvider || !result.Capabilities.DefinitionProvider || result.Capabilities.CompletionProvider == nil {
+		t.Fatalf("unexpected capabilities: %+v", result.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+	return c
+}
+
+func TestServerDiagnostics(t *testing.T) {
+	c := newInitializedClient(t, nil)
+
+	diags := c.open("file:///test/en.lxn", "key: ${foo:unknown}\n")
+	if len(diags.Diagnostics) != 1 {
+		t.Fatalf("unexpected number of diagnostics: %+v", diags.Diagnostics)
+	}
+	if d := diags.Diagnostics[0]; d.Range != (Range{Start: Position{Line: 0, Character: 5}, End: Position{Line: 0, Character: 19}}) || d.Message != "invalid replacement type: unknown" {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: "file:///test/en.lxn"},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: "key: ${foo:number}\n"}},
+	})
+	if diags := c.diagnostics(); len(diags.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics: %+v", diags.Diagnostics)
+	}
+}
+
+func TestServerInvalidContentLength(t *testing.T) {
+	c := newInitializedClient(t, nil)
+
+	for _, length := range []string{"-1", "99999999999", "abc"} {
+		if _, err := io.WriteString(c.w, "Content-Length: "+length+"\r\n\r\n"); err != nil {
+			t.Fatalf("error writing header: %v", err)
+		}
+		msg := c.read()
+		if msg.Error == nil || msg.Error.Code != invalidRequest {
+			t.Errorf("unexpected response for content length %s: %+v", length, msg)
+		}
+	}
+
+	// The server keeps serving after the invalid headers.
+	if diags := c.open("file:///test/en.lxn", "key: text\n"); len(diags.Diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics: %+v", diags.Diagnostics)
+	}
+}
+
+func TestServerCompletion(t *testing.T) {
+	c := newInitializedClient(t, nil)
+	c.open("file:///test/en.lxn", "a: ${n:pl}\nb: ${n:plural .o}\nc: ${n:money .}\nd: ${n:select .}\ne: ${n:plural .one{x}\n")
+
+	tests := []struct {
+		pos      Position
+		expected []string
+	}{
+		{Position{Line: 0, Character: 9}, []string{"string", "pluralrange", "tag"}},
+		{Position{Line: 1, Character: 16}, []string{"ordinal", "one", "other"}},
+		{Position{Line: 2, Character: 14}, []string{"currency"}},
+		{Position{Line: 3, Character: 15}, []string{"default"}},
+		{Position{Line: 4, Character: 19}, nil}, // in an option value
+	}
+	for _, test := range tests {
+		var list CompletionList
+		c.call("textDocument/completion", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: "file:///test/en.lxn"},
+			Position:     test.pos,
+		}, &list)
+
+		labels := make(map[string]bool)
+		for _, item := range list.Items {
+			labels[item.Label] = true
+		}
+		if len(test.expected) == 0 && len(labels) != 0 {
+			t.Errorf("unexpected completion at %+v: %v", test.pos, labels)
+		}
+		for _, label := range test.expected {
+			if !labels[label] {
+				t.Errorf("missing completion at %+v: %s", test.pos, label)
+			}
+		}
+	}
+}
+
+func TestServerHover(t *testing.T) {
+	c := newInitializedClient(t, nil)
+	c.open("file:///test/messages.lxn", "key: ${n:plural .ordinal .one{${#}st} .other{${#}th}} ${s}\n")
+
+	var hover *Hover
+	c.call("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///test/messages.lxn"},
+		Position:     Position{Line: 0, Character: 8},
+	}, &hover)
+	switch {
+	case hover == nil:
+		t.Fatal("missing hover")
+	case !strings.Contains(hover.Contents.Value, "ordinal plural rules for en"):
+		t.Errorf("unexpected hover: %s", hover.Contents.Value)
+	case !strings.Contains(hover.Contents.Value, "`one`: n % 10 = 1"):
+		t.Errorf("missing plural rule: %s", hover.Contents.Value)
+	}
+
+	hover = nil
+	c.call("textDocument/hover", TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///test/messages.lxn"},
+		Position:     Position{Line: 0, Character: 56},
+	}, &hover)
+	if hover != nil {
+		t.Errorf("unexpected hover: %+v", hover)
+	}
+}
+
+func TestServerDefinition(t *testing.T) {
+	files := map[string]string{
+		"/test/common.lxn": "[[common]]\nname: lxn\n",
+	}
+	c := newInitializedClient(t, files)
+	c.open("file:///test/en.lxn", "@include \"common.lxn\"\n[[sec]]\ngreeting: Hi\nfull: ${@greeting} ${@common.name}\n")
+
+	tests := []struct {
+		pos      Position
+		expected Location
+	}{
+		{Position{Line: 3, Character: 9}, Location{URI: "file:///test/en.lxn", Range: Range{Start: Position{Line: 2}, End: Position{Line: 2, Character: 8}}}},
+		{Position{Line: 3, Character: 22}, Location{URI: "file:///test/common.lxn", Range: Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 4}}}},
+	}
+	for _, test := range tests {
+		var locs []Location
+		c.call("textDocument/definition", TextDocumentPositionParams{
+			TextDocument: TextDocumentIdentifier{URI: "file:///test/en.lxn"},
+			Position:     test.pos,
+		}, &locs)
+		if len(locs) != 1 || locs[0] != test.expected {
+			t.Errorf("unexpected definition at %+v: %+v", test.pos, locs)
+		}
+	}
+}
+
+func TestServerDocumentSymbols(t *testing.T) {
+	c := newInitializedClient(t, nil)
+	c.open("file:///test/en.lxn", "top: text\n[[sec]]\none: 1\ntwo:\n\t2\n")
+
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", map[string]any{
+		"textDocument": TextDocumentIdentifier{URI: "file:///test/en.lxn"},
+	}, &symbols)
+
+	if len(symbols) != 2 || symbols[0].Name != "top" || symbols[1].Name != "sec" {
+		t.Fatalf("unexpected symbols: %+v", symbols)
+	}
+	sec := symbols[1]
+	if len(sec.Children) != 2 || sec.Children[0].Name != "one" || sec.Children[1].Name != "two" {
+		t.Fatalf("unexpected section symbols: %+v", sec.Children)
+	}
+	if sec.Range.End != (Position{Line: 4, Character: 2}) {
+		t.Errorf("unexpected section range: %+v", sec.Range)
+	}
+}
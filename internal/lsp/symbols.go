@@ -0,0 +1,106 @@
+package lsp
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// definition returns the location of the message which is referenced by the
+// replacement at the given offset. The message is searched in the document and
+// in the files included by the document.
+func (s *Server) definition(doc *document, offset int) any {
+	expr := doc.replacementAt(offset)
+	if expr == nil || !strings.HasPrefix(expr.Key, "@") {
+		return nil
+	}
+
+	section, key := doc.sectionAt(expr.Start().Offset), expr.Key[1:]
+	if idx := strings.LastIndexByte(key, '.'); idx >= 0 {
+		section, key = key[:idx], key[idx+1:]
+	}
+
+	visited := make(map[string]bool)
+	var find func(d *document) *Location
+	find = func(d *document) *Location {
+		visited[filepath.Clean(d.filename)] = true
+
+		currentSection := ""
+		var includes []string
+		for _, decl := range d.file.Decls {
+			switch decl := decl.(type) {
+			case *lxn.SectionDecl:
+				currentSection = decl.Name
+			case *lxn.IncludeDecl:
+				filename := filepath.FromSlash(decl.Path)
+				if !filepath.IsAbs(filename) {
+					filename = filepath.Join(filepath.Dir(d.filename), filename)
+				}
+				includes = append(includes, filename)
+			case *lxn.MessageDecl:
+				if currentSection == section && decl.Key == key {
+					return &Location{URI: d.uri, Range: keyRange(d, decl)}
+				}
+			}
+		}
+
+		for _, filename := range includes {
+			if visited[filepath.Clean(filename)] {
+				continue
+			}
+			src, err := s.readFile(filename)
+			if err != nil {
+				continue
+			}
+			if loc := find(newDocument(pathToURI(filename), string(src))); loc != nil {
+				return loc
+			}
+		}
+		return nil
+	}
+
+	if loc := find(doc); loc != nil {
+		return []Location{*loc}
+	}
+	return nil
+}
+
+// documentSymbols returns the sections and messages of the document. Messages
+// are nested in their section.
+func documentSymbols(doc *document) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	var section *DocumentSymbol
+	for _, decl := range doc.file.Decls {
+		switch decl := decl.(type) {
+		case *lxn.SectionDecl:
+			symbols = append(symbols, DocumentSymbol{
+				Name:           decl.Name,
+				Kind:           symbolNamespace,
+				Range:          doc.nodeRange(decl),
+				SelectionRange: doc.nodeRange(decl),
+			})
+			section = &symbols[len(symbols)-1]
+
+		case *lxn.MessageDecl:
+			sym := DocumentSymbol{
+				Name:           decl.Key,
+				Kind:           symbolString,
+				Range:          doc.nodeRange(decl),
+				SelectionRange: keyRange(doc, decl),
+			}
+			if section == nil {
+				symbols = append(symbols, sym)
+			} else {
+				section.Children = append(section.Children, sym)
+				section.Range.End = sym.Range.End
+			}
+		}
+	}
+	return symbols
+}
+
+func keyRange(doc *document, decl *lxn.MessageDecl) Range {
+	start := decl.Start().Offset
+	return Range{Start: doc.position(start), End: doc.position(start + len(decl.Key))}
+}
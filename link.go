@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/liblxn/lxnc/locale"
+	"github.com/liblxn/lxnc/lxn"
+)
+
+// link reads catalog and dictionary files and merges their messages, so they
+// can be written as a dictionary with the locale data of the current CLDR
+// version. The locales of the files have to be compatible: all of them have to
+// be the locale of the dictionary, which is the most specific one, or one of
+// its parent locales. Messages of a parent locale are only used if the
+// dictionary's locale does not define them (e.g. 'de' messages fill the gaps of
+// 'de-AT' messages). Messages of the same locale are merged like in the 'bundle'
+// command.
+//
+// The display names of the input dictionaries are linked again for the
+// returned locales.
+func link(opts options) (*lxn.Catalog, []string, error) {
+	if len(opts.inputFiles) == 0 {
+		return nil, nil, nil
+	}
+
+	var (
+		inputs []linkInput
+		target locale.Locale
+		depth  int
+		names  = make(map[string]struct{})
+	)
+	for _, inputFile := range opts.inputFiles {
+		input, err := readLinkInput(inputFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		if d := len(localeChain(input.loc)); d > depth {
+			target, depth = input.loc, d
+		}
+		for id := range input.displayNames {
+			names[id] = struct{}{}
+		}
+		inputs = append(inputs, input)
+	}
+
+	// Each locale of the chain has its own merger, so conflicts are only
+	// resolved between files of the same locale.
+	chain := localeChain(target)
+	mergers := make([]*merger, len(chain))
+	for _, input := range inputs {
+		idx := indexOfLocale(chain, input.loc)
+		if idx < 0 {
+			return nil, nil, fmt.Errorf("incompatible locales: %s and %s", target, input.loc)
+		}
+		if mergers[idx] == nil {
+			m, err := newMerger(opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			mergers[idx] = m
+		}
+		mergers[idx].add(input.filename, input.messages)
+	}
+
+	var sources []lxn.FallbackSource
+	for i, m := range mergers {
+		if m == nil {
+			continue
+		}
+		messages, err := m.merge()
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = append(sources, lxn.FallbackSource{
+			LocaleID: chain[i].String(),
+			Messages: messages,
+		})
+	}
+
+	var messages []lxn.Message
+	if len(sources) == 1 {
+		messages = sources[0].Messages
+	} else {
+		var inherited []lxn.InheritedMessage
+		messages, inherited = lxn.ResolveFallbacks(sources)
+		for _, msg := range inherited {
+			fmt.Fprintf(os.Stderr, "inherited %s from %s\n", lxn.MessageName(msg.Section, msg.Key), msg.LocaleID)
+		}
+	}
+
+	displayNames := make([]string, 0, len(names))
+	for id := range names {
+		displayNames = append(displayNames, id)
+	}
+	sort.Strings(displayNames)
+
+	return &lxn.Catalog{
+		LocaleID: target.String(),
+		Messages: messages,
+	}, displayNames, nil
+}
+
+// linkInput holds the messages of a catalog or dictionary file.
+type linkInput struct {
+	filename     string
+	loc          locale.Locale
+	messages     []lxn.Message
+	displayNames map[string]string // only set for dictionaries
+}
+
+func readLinkInput(filename string) (linkInput, error) {
+	data, err := readInput(filename)
+	if err != nil {
+		return linkInput{}, err
+	}
+
+	c, err := lxn.ReadContainer(bytes.NewReader(data))
+	if err != nil {
+		return linkInput{}, fmt.Errorf("unable to decode catalog or dictionary file %s: %v", filename, err)
+	}
+
+	// Dictionaries are linked again, so their locale data is replaced by the
+	// data of the current CLDR version.
+	input := linkInput{filename: filename}
+	localeID := ""
+	if c.Catalog != nil {
+		localeID, input.messages = c.Catalog.LocaleID, c.Catalog.Messages
+	} else {
+		dic := c.Dictionary
+		localeID, input.messages, input.displayNames = dic.Locale.ID, dic.Messages, dic.Locale.DisplayNames
+	}
+
+	input.loc, err = locale.New(localeID)
+	if err != nil {
+		return linkInput{}, fmt.Errorf("invalid locale in %s: %v", filename, err)
+	}
+	return input, nil
+}
+
+// localeChain returns the locale and all of its parent locales, nearest parent
+// first.
+func localeChain(loc locale.Locale) []locale.Locale {
+	chain := []locale.Locale{loc}
+	for parent := loc.Parent(); parent != loc; parent = loc.Parent() {
+		chain = append(chain, parent)
+		loc = parent
+	}
+	return chain
+}
+
+func indexOfLocale(locales []locale.Locale, loc locale.Locale) int {
+	for i, l := range locales {
+		if l == loc {
+			return i
+		}
+	}
+	return -1
+}
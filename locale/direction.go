@@ -0,0 +1,50 @@
+// This file was generated by the 'generate' command. Do not edit.
+// CLDR version: 44
+
+package locale
+
+import (
+	"sort"
+)
+
+// Direction specifies the order in which the characters of a locale are written.
+type Direction int
+
+// Available directions.
+const (
+	LeftToRight Direction = iota
+	RightToLeft
+)
+
+// String returns the CLDR name of the direction.
+func (d Direction) String() string {
+	switch d {
+	case LeftToRight:
+		return "left-to-right"
+	case RightToLeft:
+		return "right-to-left"
+	default:
+		return ""
+	}
+}
+
+// Direction returns the character order of the locale, which is either left-to-right
+// (e.g. English) or right-to-left (e.g. Arabic).
+func (l Locale) Direction() Direction {
+	if rtlLocales.rightToLeft(tagID(l)) {
+		return RightToLeft
+	}
+	return LeftToRight
+}
+
+// The direction lookup is an ordered list of the tag ids of all locales which
+// are written from right to left. All other locales are written from left to
+// right.
+type directionLookup []tagID
+
+func (l directionLookup) rightToLeft(id tagID) bool {
+	idx := sort.Search(len(l), func(i int) bool {
+		return l[i] >= id
+	})
+	return idx < len(l) && l[idx] == id
+}
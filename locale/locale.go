@@ -10,6 +10,10 @@ import (
 
 const root Locale = 965
 
+// CLDRVersion is the version of the CLDR data, which the locales are generated
+// from. It is empty if the version is unknown.
+const CLDRVersion = "44"
+
 // Locale represents a reference to the data of a CLDR locale.
 type Locale tagID
 
@@ -94,6 +98,13 @@ func (l Locale) String() string {
 	return string(buf[:n])
 }
 
+// Parent returns the parent locale of l. The parent is either defined by the CLDR
+// data or determined by truncating the subtags of l. The parent of the root locale
+// is the root locale itself.
+func (l Locale) Parent() Locale {
+	return l.parent()
+}
+
 func (l Locale) tagIDs() (langID, scriptID, regionID) {
 	tag := localeTags.tag(tagID(l))
 	return tag.langID(), tag.scriptID(), tag.regionID()
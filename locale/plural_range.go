@@ -0,0 +1,62 @@
+// This file was generated by the 'generate' command. Do not edit.
+// CLDR version: 44
+
+package locale
+
+// PluralRange defines the plural category of a number range (e.g. "1–3 days"), where
+// the start and the end of the range have the given plural categories.
+type PluralRange struct {
+	Start  PluralCategory
+	End    PluralCategory
+	Result PluralCategory
+}
+
+// PluralRanges returns the plural ranges for cardinals in the given locale.
+func PluralRanges(loc Locale) []PluralRange {
+	return lookupPluralRanges(loc, pluralRanges)
+}
+
+// PluralRangeCategory returns the plural category of a number range in the given
+// locale, where start and end are the plural categories of the range bounds. If
+// the locale does not define a category for the range, the end category will be
+// returned.
+func PluralRangeCategory(loc Locale, start PluralCategory, end PluralCategory) PluralCategory {
+	for _, rng := range PluralRanges(loc) {
+		if rng.Start == start && rng.End == end {
+			return rng.Result
+		}
+	}
+	return end
+}
+
+func lookupPluralRanges(loc Locale, lookup pluralRangeLookup) []PluralRange {
+	if loc == 0 {
+		panic("invalid locale")
+	}
+
+	lang, _, _ := loc.tagIDs()
+	ranges := lookup[lang]
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	res := make([]PluralRange, len(ranges))
+	for i, rng := range ranges {
+		res[i] = PluralRange{
+			Start:  PluralCategory(rng.start()),
+			End:    PluralCategory(rng.end()),
+			Result: PluralCategory(rng.result()),
+		}
+	}
+	return res
+}
+
+// A plural range consists of the plural categories of the range start, the range
+// end, and the resulting category of the whole range.
+type pluralRange uint16
+
+func (r pluralRange) start() uint  { return uint(r>>6) & 0x7 }
+func (r pluralRange) end() uint    { return uint(r>>3) & 0x7 }
+func (r pluralRange) result() uint { return uint(r) & 0x7 }
+
+type pluralRangeLookup map[langID][]pluralRange
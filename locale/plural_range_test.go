@@ -0,0 +1,119 @@
+// This file was generated by the 'generate' command. Do not edit.
+// CLDR version: 44
+
+package locale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupPluralRanges(t *testing.T) {
+	loc, err := New("en")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lang, _, _ := loc.tagIDs()
+	lookup := pluralRangeLookup{
+		lang: {0x6d, 0x149},
+	}
+
+	expected := []PluralRange{
+		{Start: One, End: Other, Result: Other},
+		{Start: Other, End: One, Result: One},
+	}
+	if ranges := lookupPluralRanges(loc, lookup); !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("unexpected plural ranges: %+v", ranges)
+	}
+	if ranges := lookupPluralRanges(loc, pluralRangeLookup{}); ranges != nil {
+		t.Errorf("unexpected plural ranges: %+v", ranges)
+	}
+}
+
+func TestPluralRangeCategory(t *testing.T) {
+	for _, data := range pluralRanges {
+		for _, rng := range data {
+			if rng.result() > 5 {
+				t.Errorf("invalid plural range result: %d", rng.result())
+			}
+		}
+	}
+
+	loc, err := New("und")
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case PluralRangeCategory(loc, Other, One) != One:
+		t.Errorf("unexpected fallback category for the root locale")
+	}
+}
+
+func TestCLDRPluralRanges(t *testing.T) {
+	tests := []struct {
+		lang     string
+		start    PluralCategory
+		end      PluralCategory
+		expected PluralCategory
+	}{
+		{lang: "ar", start: Zero, end: One, expected: Zero},
+		{lang: "ar", start: Zero, end: Two, expected: Zero},
+		{lang: "ar", start: Zero, end: Few, expected: Few},
+		{lang: "ar", start: Zero, end: Many, expected: Many},
+		{lang: "ar", start: Zero, end: Other, expected: Other},
+		{lang: "ar", start: One, end: Two, expected: Other},
+		{lang: "ar", start: One, end: Few, expected: Few},
+		{lang: "ar", start: One, end: Many, expected: Many},
+		{lang: "ar", start: One, end: Other, expected: Other},
+		{lang: "ar", start: Two, end: Few, expected: Few},
+		{lang: "ar", start: Two, end: Many, expected: Many},
+		{lang: "ar", start: Two, end: Other, expected: Other},
+		{lang: "ar", start: Few, end: Few, expected: Few},
+		{lang: "ar", start: Few, end: Many, expected: Many},
+		{lang: "ar", start: Few, end: Other, expected: Other},
+		{lang: "ar", start: Many, end: Few, expected: Few},
+		{lang: "ar", start: Many, end: Many, expected: Many},
+		{lang: "ar", start: Many, end: Other, expected: Other},
+		{lang: "ar", start: Other, end: One, expected: Other},
+		{lang: "ar", start: Other, end: Two, expected: Other},
+		{lang: "ar", start: Other, end: Few, expected: Few},
+		{lang: "ar", start: Other, end: Many, expected: Many},
+		{lang: "ar", start: Other, end: Other, expected: Other},
+		{lang: "de", start: One, end: Other, expected: Other},
+		{lang: "de", start: Other, end: One, expected: One},
+		{lang: "de", start: Other, end: Other, expected: Other},
+		{lang: "en", start: One, end: Other, expected: Other},
+		{lang: "en", start: Other, end: One, expected: Other},
+		{lang: "en", start: Other, end: Other, expected: Other},
+		{lang: "fr", start: One, end: One, expected: One},
+		{lang: "fr", start: One, end: Many, expected: Other},
+		{lang: "fr", start: One, end: Other, expected: Other},
+		{lang: "fr", start: Many, end: Many, expected: Other},
+		{lang: "fr", start: Other, end: Many, expected: Other},
+		{lang: "fr", start: Other, end: Other, expected: Other},
+	}
+
+	for _, test := range tests {
+		loc, err := New(test.lang)
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error for %s: %v", test.lang, err)
+		case PluralRangeCategory(loc, test.start, test.end) != test.expected:
+			t.Errorf("unexpected category for %s %d+%d: %d", test.lang, test.start, test.end, PluralRangeCategory(loc, test.start, test.end))
+		}
+	}
+}
+
+func TestPluralRange(t *testing.T) {
+	const rng pluralRange = 0x5d
+
+	if start := rng.start(); start != 1 {
+		t.Errorf("unexpected start category: %d", start)
+	}
+	if end := rng.end(); end != 3 {
+		t.Errorf("unexpected end category: %d", end)
+	}
+	if result := rng.result(); result != 5 {
+		t.Errorf("unexpected result category: %d", result)
+	}
+}
@@ -702,3 +702,16 @@ func TestZeros(t *testing.T) {
 		}
 	}
 }
+
+func TestRtlLocales(t *testing.T) {
+	for i := 1; i < len(rtlLocales); i++ {
+		if rtlLocales[i-1] >= rtlLocales[i] {
+			t.Fatalf("unordered tag ids at index %d", i)
+		}
+	}
+	for _, id := range rtlLocales {
+		if !rtlLocales.rightToLeft(id) {
+			t.Errorf("expected tag id %d to be right-to-left", uint(id))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package lxn
+
+// Node is implemented by all nodes of the syntax tree. The start position points
+// to the first character of the node and the end position right after the last
+// character of the node.
+type Node interface {
+	Start() Pos
+	End() Pos
+}
+
+// Span holds the source range of a node.
+type Span struct {
+	StartPos Pos
+	EndPos   Pos
+}
+
+// Start returns the position of the node's first character.
+func (s Span) Start() Pos {
+	return s.StartPos
+}
+
+// End returns the position right after the node's last character.
+func (s Span) End() Pos {
+	return s.EndPos
+}
+
+// File is the syntax tree of a single lxn file. The declarations are stored in
+// the order of their appearance.
+type File struct {
+	Span
+	Name  string
+	Decls []Decl
+}
+
+// Decl is a top-level declaration of a file. It is either a *Comment, a
+// *SectionDecl, an *IncludeDecl, or a *MessageDecl.
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Comment is a single comment line. Doc comments start with three slashes and
+// hold the notes for the translators of the following message. The text does not
+// contain the leading slashes.
+type Comment struct {
+	Span
+	Text string
+	Doc  bool
+}
+
+// SectionDecl is a section header ([[name]]), which defines the section of all
+// following messages.
+type SectionDecl struct {
+	Span
+	Name string
+}
+
+// IncludeDecl is an include directive (@include "path").
+type IncludeDecl struct {
+	Span
+	Path string
+}
+
+// MessageDecl is the declaration of a message. The body is split into text,
+// line breaks, and replacements. If the message is indented, the body starts on
+// the line after the key. A verbatim message (key: |) keeps its line breaks as
+// part of the text.
+type MessageDecl struct {
+	Span
+	Key      string
+	Indented bool
+	Verbatim bool
+	Body     []Fragment
+}
+
+// Fragment is a part of a message body. It is either a *Text, a *LineBreak, or
+// a *ReplacementExpr.
+type Fragment interface {
+	Node
+	fragmentNode()
+}
+
+// Text is a piece of literal text.
+type Text struct {
+	Span
+	Value string
+}
+
+// LineBreak is a line break in the body of a message, including the indentation
+// of the following line. Line breaks are folded into a single space.
+type LineBreak struct {
+	Span
+}
+
+// ReplacementExpr is a replacement (${key:type .option{value}}). The key is "#"
+// for the plural count placeholder and starts with '@' for message references.
+// The type is empty if it is not specified.
+type ReplacementExpr struct {
+	Span
+	Key     string
+	Type    string
+	Options []*Option
+}
+
+// Option is an option of a replacement. Options in brackets (e.g. .[7]) keep
+// their brackets in the name. The content of a markup tag (${key:tag{content}})
+// is an option without a name. If the option has no value in braces, HasValue
+// is false.
+type Option struct {
+	Span
+	Name     string
+	HasValue bool
+	Value    []Fragment
+}
+
+func (*Comment) declNode()     {}
+func (*SectionDecl) declNode() {}
+func (*IncludeDecl) declNode() {}
+func (*MessageDecl) declNode() {}
+
+func (*Text) fragmentNode()            {}
+func (*LineBreak) fragmentNode()       {}
+func (*ReplacementExpr) fragmentNode() {}
@@ -0,0 +1,46 @@
+package lxn
+
+// IsolateReplacements marks the replacements of the given messages for bidi
+// isolation. Plural, plural range, and select replacements are not isolated
+// themselves, since they are replaced by translated text, but the replacements
+// of their nested messages are. Markup replacements are never isolated.
+func IsolateReplacements(messages []Message) {
+	for i := range messages {
+		isolateReplacements(&messages[i])
+	}
+}
+
+func isolateReplacements(msg *Message) {
+	for i := range msg.Replacements {
+		repl := &msg.Replacements[i]
+		switch details := repl.Details.Value.(type) {
+		case PluralDetails:
+			for cat, variant := range details.Variants {
+				isolateReplacements(&variant)
+				details.Variants[cat] = variant
+			}
+			for n, custom := range details.Custom {
+				isolateReplacements(&custom)
+				details.Custom[n] = custom
+			}
+			for n, custom := range details.CustomDecimals {
+				isolateReplacements(&custom)
+				details.CustomDecimals[n] = custom
+			}
+		case PluralRangeDetails:
+			for cat, variant := range details.Variants {
+				isolateReplacements(&variant)
+				details.Variants[cat] = variant
+			}
+		case SelectDetails:
+			for key, c := range details.Cases {
+				isolateReplacements(&c)
+				details.Cases[key] = c
+			}
+		case MarkupDetails:
+			// Markup tags do not have a value.
+		default:
+			repl.Isolate = true
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package lxn
+
+import "testing"
+
+func TestIsolateReplacements(t *testing.T) {
+	messages := []Message{
+		{
+			Key:  "greeting",
+			Text: []string{"مرحبا ", ""},
+			Replacements: []Replacement{
+				{Key: "name", TextPos: 1, Type: StringReplacement, Details: ReplacementDetails{Value: EmptyDetails{}}},
+			},
+		},
+		{
+			Key: "items",
+			Replacements: []Replacement{
+				{
+					Key:  "count",
+					Type: PluralReplacement,
+					Details: ReplacementDetails{Value: PluralDetails{
+						Variants: map[PluralCategory]Message{
+							Other: {
+								Text:         []string{"", " عناصر"},
+								Replacements: []Replacement{{Key: "count", Type: NumberReplacement, Details: ReplacementDetails{Value: EmptyDetails{}}}},
+							},
+						},
+						Custom: map[int64]Message{},
+					}},
+				},
+				{
+					Key:  "gender",
+					Type: SelectReplacement,
+					Details: ReplacementDetails{Value: SelectDetails{
+						Cases: map[string]Message{
+							"female": {
+								Text:         []string{""},
+								Replacements: []Replacement{{Key: "name", Type: StringReplacement, Details: ReplacementDetails{Value: EmptyDetails{}}}},
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	IsolateReplacements(messages)
+
+	if !messages[0].Replacements[0].Isolate {
+		t.Errorf("expected string replacement to be isolated")
+	}
+
+	plural := messages[1].Replacements[0]
+	switch {
+	case plural.Isolate:
+		t.Errorf("unexpected isolation for plural replacement")
+	case !plural.Details.Value.(PluralDetails).Variants[Other].Replacements[0].Isolate:
+		t.Errorf("expected nested plural replacement to be isolated")
+	}
+
+	sel := messages[1].Replacements[1]
+	switch {
+	case sel.Isolate:
+		t.Errorf("unexpected isolation for select replacement")
+	case !sel.Details.Value.(SelectDetails).Cases["female"].Replacements[0].Isolate:
+		t.Errorf("expected nested select replacement to be isolated")
+	}
+}
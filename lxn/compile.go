@@ -1,33 +1,300 @@
 package lxn
 
 import (
+	"io"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sync"
 
+	"github.com/liblxn/lxnc/internal/errors"
 	"github.com/liblxn/lxnc/locale"
 )
 
 // CompileMessages parses the given files and returns all messages found in
-// these files.
+// these files. Message references are resolved across all files.
 func CompileMessages(filenames ...string) ([]Message, error) {
-	p := parser{}
-	msgs := make([]Message, 0, 128)
-	for _, filename := range filenames {
-		bytes, err := os.ReadFile(filename)
-		if err != nil {
-			return nil, err
+	msgs, err := ParseMessages(filenames...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ResolveReferences(msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// CompileMessagesConcurrent works like CompileMessages, but parses the files in
+// parallel with the given number of workers. If workers is not positive, the
+// number of CPUs is used.
+func CompileMessagesConcurrent(workers int, filenames ...string) ([]Message, error) {
+	msgs, err := ParseMessagesConcurrent(workers, filenames...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ResolveReferences(msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// ParseMessages parses the given files and returns all messages found in these
+// files, including the messages of included files. In contrast to
+// CompileMessages, message references are left unresolved, so they have to be
+// resolved with ResolveReferences before the messages are encoded.
+//
+// A file which is also included by one of the other files only contributes its
+// messages once, at the position of the include directive.
+//
+// All files are parsed, even if some of them fail. The errors of all files are
+// returned as a single ErrorList.
+func ParseMessages(filenames ...string) ([]Message, error) {
+	results := make([]parseResult, len(filenames))
+	for i, filename := range filenames {
+		results[i] = parseMessageFile(filename, os.ReadFile, false)
+	}
+	return mergeParseResults(filenames, results)
+}
+
+// ParseMessagesConcurrent works like ParseMessages, but parses the files in
+// parallel with the given number of workers. If workers is not positive, the
+// number of CPUs is used. The messages and errors are returned in the order of
+// the files, so the result is the same as for ParseMessages.
+func ParseMessagesConcurrent(workers int, filenames ...string) ([]Message, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(filenames))
+
+	results := make([]parseResult, len(filenames))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				results[i] = parseMessageFile(filenames[i], os.ReadFile, false)
+			}
+		}()
+	}
+	for i := range filenames {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return mergeParseResults(filenames, results)
+}
+
+type parseResult struct {
+	msgs     []Message
+	err      error
+	included []string // files reached by include directives
+}
+
+// parseMessageFile reads a file with readFile and parses it. The included files
+// are read with readFile as well.
+func parseMessageFile(filename string, readFile func(string) ([]byte, error), slashPaths bool) parseResult {
+	bytes, err := readFile(filename)
+	if err != nil {
+		return parseResult{err: err}
+	}
+
+	p := parser{readFile: readFile, slashPaths: slashPaths}
+	msgs, err := p.Parse(filename, bytes)
+	return parseResult{msgs: msgs, err: err, included: p.included}
+}
+
+// mergeParseResults concatenates the messages of all files. A file which is
+// included by one of the other files is skipped, since its messages are already
+// part of the including file. If any file failed, the errors of all files are
+// returned instead.
+func mergeParseResults(filenames []string, results []parseResult) ([]Message, error) {
+	included := make([][]string, len(results))
+	for i, r := range results {
+		included[i] = r.included
+	}
+	for i, skip := range IncludedFiles(filenames, included) {
+		if skip {
+			results[i] = parseResult{}
 		}
+	}
 
-		m, err := p.Parse(filename, bytes)
-		if err != nil {
+	var errs ErrorList
+	n := 0
+	for _, r := range results {
+		switch err := r.err.(type) {
+		case nil:
+		case ErrorList:
+			errs = append(errs, err...)
+		default:
+			errs = append(errs, err)
+		}
+		n += len(r.msgs)
+	}
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	msgs := make([]Message, 0, n)
+	for _, r := range results {
+		msgs = append(msgs, r.msgs...)
+	}
+	return msgs, nil
+}
+
+// IncludedFiles reports for each of the given files whether it is skipped,
+// because its messages are already part of another file which includes it.
+// included holds the files reached by the include directives of each file.
+//
+// A file is only skipped if it is reached by a file which is not skipped itself.
+// Files which include each other are not reached by any such file, so the first
+// of them is kept and reports the include cycle.
+func IncludedFiles(filenames []string, included [][]string) []bool {
+	index := make(map[string]int, len(filenames))
+	for i, filename := range filenames {
+		index[filepath.Clean(filename)] = i
+	}
+
+	reaches := make([][]int, len(filenames)) // other files reached by a file
+	reached := make([]bool, len(filenames))
+	for i := range filenames {
+		for _, filename := range included[i] {
+			if j, has := index[filepath.Clean(filename)]; has && j != i {
+				reaches[i] = append(reaches[i], j)
+				reached[j] = true
+			}
+		}
+	}
+
+	skipped := make([]bool, len(filenames))
+	keep := func(i int) {
+		for _, j := range reaches[i] {
+			skipped[j] = true
+		}
+	}
+	for i := range filenames {
+		if !reached[i] {
+			keep(i)
+		}
+	}
+	for i := range filenames {
+		if reached[i] && !skipped[i] {
+			keep(i)
+		}
+	}
+	return skipped
+}
+
+// CompileMessagesFS works like CompileMessages, but reads the files from the
+// given file system, e.g. an embed.FS or a zip archive. The files are selected
+// with glob patterns as understood by fs.Glob, and each pattern has to match at
+// least one file. A file which is matched by several patterns is compiled only
+// once. The file names in error positions and include directives are paths
+// within the file system.
+func CompileMessagesFS(fsys fs.FS, patterns ...string) ([]Message, error) {
+	msgs, err := ParseMessagesFS(fsys, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ResolveReferences(msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// ParseMessagesFS works like ParseMessages, but reads the files from the given
+// file system. The files are selected with glob patterns (see CompileMessagesFS).
+func ParseMessagesFS(fsys fs.FS, patterns ...string) ([]Message, error) {
+	var filenames []string
+	seen := make(map[string]struct{})
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		switch {
+		case err != nil:
 			return nil, err
+		case len(matches) == 0:
+			return nil, errors.Newf("no files match pattern %q", pattern)
 		}
+		for _, filename := range matches {
+			if _, has := seen[filename]; !has {
+				seen[filename] = struct{}{}
+				filenames = append(filenames, filename)
+			}
+		}
+	}
 
-		msgs = append(msgs, m...)
+	readFile := func(filename string) ([]byte, error) {
+		return fs.ReadFile(fsys, filename)
+	}
+	results := make([]parseResult, len(filenames))
+	for i, filename := range filenames {
+		results[i] = parseMessageFile(filename, readFile, true)
 	}
+	return mergeParseResults(filenames, results)
+}
+
+// NamedReader is a reader of lxn source code together with a virtual file name,
+// e.g. for sources which are held in memory.
+type NamedReader struct {
+	Name string // slash-separated path, which is used in error positions
+	io.Reader
+}
 
+// CompileMessagesReaders works like CompileMessages, but reads the sources from
+// the given readers. The include directives of a source are resolved against
+// the names of the other sources.
+func CompileMessagesReaders(sources ...NamedReader) ([]Message, error) {
+	msgs, err := ParseMessagesReaders(sources...)
+	if err != nil {
+		return nil, err
+	}
+	if err := ResolveReferences(msgs); err != nil {
+		return nil, err
+	}
 	return msgs, nil
 }
 
+// ParseMessagesReaders works like ParseMessages, but reads the sources from the
+// given readers (see CompileMessagesReaders).
+func ParseMessagesReaders(sources ...NamedReader) ([]Message, error) {
+	files := make(map[string][]byte, len(sources))
+	for _, src := range sources {
+		content, err := io.ReadAll(src)
+		if err != nil {
+			return nil, errors.Newf("error reading %s: %v", src.Name, err)
+		}
+		files[path.Clean(src.Name)] = content
+	}
+
+	readFile := func(filename string) ([]byte, error) {
+		content, has := files[path.Clean(filename)]
+		if !has {
+			return nil, &fs.PathError{Op: "open", Path: filename, Err: fs.ErrNotExist}
+		}
+		return content, nil
+	}
+	filenames := make([]string, len(sources))
+	results := make([]parseResult, len(sources))
+	for i, src := range sources {
+		filenames[i] = src.Name
+		results[i] = parseMessageFile(src.Name, readFile, true)
+	}
+	return mergeParseResults(filenames, results)
+}
+
+// ParseSource parses the source of a single lxn file and returns its messages.
+// Included files are read with readFile relative to the given filename. If
+// readFile is nil, include directives are reported as errors. Message references
+// are left unresolved.
+func ParseSource(filename string, src []byte, readFile func(filename string) ([]byte, error)) ([]Message, error) {
+	p := parser{readFile: readFile}
+	return p.Parse(filename, src)
+}
+
 // CompileCatalog parses the given files and determines the locale information which is need
 // for formatting data. It returns the catalog for all the messages in the files.
 func CompileCatalog(loc locale.Locale, filenames ...string) (*Catalog, error) {
@@ -37,3 +304,23 @@ func CompileCatalog(loc locale.Locale, filenames ...string) (*Catalog, error) {
 	}
 	return NewCatalog(loc, messages), nil
 }
+
+// CompileCatalogFS works like CompileCatalog, but reads the files from the given
+// file system. The files are selected with glob patterns (see CompileMessagesFS).
+func CompileCatalogFS(loc locale.Locale, fsys fs.FS, patterns ...string) (*Catalog, error) {
+	messages, err := CompileMessagesFS(fsys, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(loc, messages), nil
+}
+
+// CompileCatalogReaders works like CompileCatalog, but reads the sources from the
+// given readers (see CompileMessagesReaders).
+func CompileCatalogReaders(loc locale.Locale, sources ...NamedReader) (*Catalog, error) {
+	messages, err := CompileMessagesReaders(sources...)
+	if err != nil {
+		return nil, err
+	}
+	return NewCatalog(loc, messages), nil
+}
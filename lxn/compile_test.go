@@ -0,0 +1,265 @@
+package lxn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseMessagesConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var filenames []string
+	for i := 0; i < 50; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("file-%d.lxn", i))
+		if err := os.WriteFile(filename, benchmarkInput(fmt.Sprintf("file-%d", i), 3), 0666); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	expected, err := ParseMessages(filenames...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, workers := range []int{0, 1, 4, 100} {
+		msgs, err := ParseMessagesConcurrent(workers, filenames...)
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error for %d workers: %v", workers, err)
+		case !reflect.DeepEqual(msgs, expected):
+			t.Errorf("unexpected messages for %d workers", workers)
+		}
+	}
+}
+
+func TestParseMessagesConcurrentWithErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "a.lxn", content: "key: ${foo:unknowntype}\n"},
+		{name: "b.lxn", content: "key: valid\n"},
+		{name: "c.lxn", content: "key: ${foo bar}\nnext: ${foo:plural .one{x}}\n"},
+	}
+
+	var filenames []string
+	for _, f := range files {
+		filename := filepath.Join(dir, f.name)
+		if err := os.WriteFile(filename, []byte(f.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+	filenames = append(filenames, filepath.Join(dir, "missing.lxn"))
+
+	_, seqErr := ParseMessages(filenames...)
+	_, err := ParseMessagesConcurrent(2, filenames...)
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"a.lxn:1:5: invalid replacement type: unknowntype",
+		"c.lxn:1:11: unexpected token 'b' ('}' expected)",
+		"c.lxn:2:6: plural option .other required",
+		"missing.lxn",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected number of errors: %d (%q)", len(errs), []error(errs))
+	}
+	for i, e := range errs {
+		if msg := e.Error(); !strings.Contains(msg, expected[i]) {
+			t.Errorf("unexpected error message: %s", msg)
+		}
+	}
+	if !reflect.DeepEqual(err, seqErr) {
+		t.Errorf("errors differ from sequential parsing: %v", seqErr)
+	}
+}
+
+func TestParseMessagesWithIncludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "a-sub.lxn", content: "[[sub]]\nkey: included\n@include \"nested/deep.lxn\"\n"},
+		{name: "b-main.lxn", content: "title: main\n@include \"a-sub.lxn\"\n"},
+		{name: "c-other.lxn", content: "other: text\n"},
+		{name: "nested/deep.lxn", content: "[[deep]]\nkey: deep\n"},
+	}
+
+	// The files are passed like the expanded files of the directory, so the
+	// included files are passed as well.
+	var filenames []string
+	for _, f := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(f.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	expected := []string{".title", "sub.key", "deep.key", ".other"}
+	for _, parse := range []func(...string) ([]Message, error){ParseMessages, func(filenames ...string) ([]Message, error) {
+		return ParseMessagesConcurrent(2, filenames...)
+	}} {
+		msgs, err := parse(filenames...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var keys []string
+		for _, msg := range msgs {
+			keys = append(keys, msg.Section+"."+msg.Key)
+		}
+		if !reflect.DeepEqual(keys, expected) {
+			t.Errorf("unexpected messages: %v", keys)
+		}
+	}
+
+	// The errors of an included file are reported once.
+	broken := filepath.Join(dir, "nested", "deep.lxn")
+	if err := os.WriteFile(broken, []byte("key: ${foo bar}\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ParseMessages(filenames...)
+	if errs, ok := err.(ErrorList); !ok || len(errs) != 1 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseMessagesWithIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "a.lxn", content: "@include \"b.lxn\"\n"},
+		{name: "b.lxn", content: "@include \"a.lxn\"\n"},
+	}
+
+	var filenames []string
+	for _, f := range files {
+		filename := filepath.Join(dir, f.name)
+		if err := os.WriteFile(filename, []byte(f.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	// Both files are included by the other one, so the first file is kept and
+	// the cycle is reported once.
+	_, err := ParseMessages(filenames...)
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := errs[0].Error(); !strings.Contains(msg, "include cycle: ") || !strings.HasSuffix(msg, "(included from "+filenames[0]+":1:0)") {
+		t.Errorf("unexpected error message: %s", msg)
+	}
+}
+
+func BenchmarkCompileMessages(b *testing.B) {
+	dir := b.TempDir()
+	var filenames []string
+	for i := 0; i < 2000; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("file-%d.lxn", i))
+		if err := os.WriteFile(filename, benchmarkInput(fmt.Sprintf("file-%d", i), 5), 0666); err != nil {
+			b.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	b.Run("sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := CompileMessages(filenames...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("concurrent", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := CompileMessagesConcurrent(0, filenames...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCompileMessagesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"de/main.lxn":          {Data: []byte("[[main]]\ntitle: Titel\n@include \"common/units.lxn\"\n")},
+		"de/common/units.lxn":  {Data: []byte("[[units]]\nmeter: ${n:number} m\n")},
+		"de/other.lxn":         {Data: []byte("[[other]]\nref: ${@main.title}\n")},
+		"de/broken/broken.lxn": {Data: []byte("key: ${foo:unknowntype}\n@include \"missing.lxn\"\n")},
+	}
+
+	msgs, err := CompileMessagesFS(fsys, "de/*.lxn", "de/main.lxn")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var keys []string
+	for _, msg := range msgs {
+		keys = append(keys, msg.Section+"."+msg.Key)
+	}
+	if expected := []string{"main.title", "units.meter", "other.ref"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected messages: %v", keys)
+	}
+
+	_, err = CompileMessagesFS(fsys, "de/broken/*.lxn")
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"de/broken/broken.lxn:1:5: invalid replacement type: unknowntype",
+		"de/broken/broken.lxn:2:0: open de/broken/missing.lxn: file does not exist",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected number of errors: %d (%q)", len(errs), []error(errs))
+	}
+	for i, e := range errs {
+		if msg := e.Error(); msg != expected[i] {
+			t.Errorf("unexpected error message: %s", msg)
+		}
+	}
+
+	if _, err := CompileMessagesFS(fsys, "fr/*.lxn"); err == nil || err.Error() != `no files match pattern "fr/*.lxn"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCompileMessagesReaders(t *testing.T) {
+	msgs, err := CompileMessagesReaders(
+		NamedReader{Name: "mem/main.lxn", Reader: strings.NewReader("title: ${@sub.key}\n@include \"sub.lxn\"\n")},
+		NamedReader{Name: "mem/other.lxn", Reader: strings.NewReader("other: text\n")},
+		NamedReader{Name: "mem/sub.lxn", Reader: strings.NewReader("[[sub]]\nkey: included\n")},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// sub.lxn is included by main.lxn, so it is only compiled as include.
+	var keys []string
+	for _, msg := range msgs {
+		keys = append(keys, msg.Section+"."+msg.Key)
+	}
+	if expected := []string{".title", "sub.key", ".other"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("unexpected messages: %v", keys)
+	}
+
+	_, err = CompileMessagesReaders(NamedReader{Name: "mem/broken.lxn", Reader: strings.NewReader("key: ${foo bar}\n")})
+	if err == nil || err.Error() != "mem/broken.lxn:1:11: unexpected token 'b' ('}' expected)" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
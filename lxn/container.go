@@ -0,0 +1,225 @@
+package lxn
+
+import (
+	"bytes"
+	"io"
+
+	msgpack "github.com/mprot/msgpack-go"
+
+	"github.com/liblxn/lxnc/internal/errors"
+	"github.com/liblxn/lxnc/locale"
+)
+
+// ContainerMagic are the magic bytes at the start of every .lxnc file.
+const ContainerMagic = "LXNC"
+
+// SchemaVersion is the version of the container format and of the message
+// schema which is written by this package. It is incremented with each
+// incompatible change, and decoders reject files with a newer version.
+const SchemaVersion = 1
+
+// ContainerKind defines the kind of value which is stored in a container.
+type ContainerKind int
+
+const (
+	KindCatalog    ContainerKind = 1
+	KindDictionary ContainerKind = 2
+)
+
+func (k ContainerKind) String() string {
+	switch k {
+	case KindCatalog:
+		return "catalog"
+	case KindDictionary:
+		return "dictionary"
+	default:
+		return "unknown"
+	}
+}
+
+// Header is the header of an .lxnc file, which follows the magic bytes. It
+// describes the encoded value and the versions of the tools which produced it.
+type Header struct {
+	SchemaVersion int
+	Kind          ContainerKind
+	LxncVersion   string // version of the compiler which wrote the file
+	CLDRVersion   string // version of the CLDR data, e.g. of the locale data of a dictionary
+}
+
+// EncodeMsgpack implements the Encoder interface for Header.
+func (h Header) EncodeMsgpack(w *msgpack.Writer) (err error) {
+	if err = w.WriteMapHeader(4); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(1); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(int64(h.SchemaVersion)); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(2); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(int64(h.Kind)); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(3); err != nil {
+		return err
+	}
+	if err = w.WriteString(h.LxncVersion); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(4); err != nil {
+		return err
+	}
+	return w.WriteString(h.CLDRVersion)
+}
+
+// DecodeMsgpack implements the Decoder interface for Header.
+func (h *Header) DecodeMsgpack(r *msgpack.Reader) error {
+	n, err := r.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		ord, err := r.ReadInt64()
+		if err != nil {
+			return err
+		}
+		switch ord {
+		case 1: // SchemaVersion
+			v, err := r.ReadInt64()
+			if err != nil {
+				return err
+			}
+			h.SchemaVersion = int(v)
+		case 2: // Kind
+			v, err := r.ReadInt64()
+			if err != nil {
+				return err
+			}
+			h.Kind = ContainerKind(v)
+		case 3: // LxncVersion
+			if h.LxncVersion, err = r.ReadString(); err != nil {
+				return err
+			}
+		case 4: // CLDRVersion
+			if h.CLDRVersion, err = r.ReadString(); err != nil {
+				return err
+			}
+		default:
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Container holds the decoded contents of an .lxnc file. Depending on the kind
+// of the header, either the catalog or the dictionary is set.
+type Container struct {
+	Header     Header
+	Catalog    *Catalog
+	Dictionary *Dictionary
+}
+
+// WriteCatalog writes the catalog as .lxnc file. The header is filled with the
+// given lxnc version and the CLDR version of the locale package.
+func WriteCatalog(w io.Writer, cat *Catalog, lxncVersion string) error {
+	return writeContainer(w, KindCatalog, lxncVersion, cat)
+}
+
+// WriteDictionary writes the dictionary as .lxnc file. The header is filled with
+// the given lxnc version and the CLDR version of the locale package.
+func WriteDictionary(w io.Writer, dic *Dictionary, lxncVersion string) error {
+	return writeContainer(w, KindDictionary, lxncVersion, dic)
+}
+
+func writeContainer(w io.Writer, kind ContainerKind, lxncVersion string, v msgpack.Encoder) error {
+	hdr := Header{
+		SchemaVersion: SchemaVersion,
+		Kind:          kind,
+		LxncVersion:   lxncVersion,
+		CLDRVersion:   locale.CLDRVersion,
+	}
+	if _, err := io.WriteString(w, ContainerMagic); err != nil {
+		return err
+	}
+	mw := msgpack.NewWriter(w)
+	if err := hdr.EncodeMsgpack(mw); err != nil {
+		return err
+	}
+	return v.EncodeMsgpack(mw)
+}
+
+// ReadContainer reads an .lxnc file. An error is returned if the file does not
+// start with the magic bytes, e.g. because it was written by an older version
+// of lxnc, or if it was written with a newer schema version.
+func ReadContainer(r io.Reader) (*Container, error) {
+	magic := make([]byte, len(ContainerMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, []byte(ContainerMagic)) {
+		return nil, errors.New("not an lxnc file (missing file header, the file may have been written by an older version of lxnc)")
+	}
+
+	mr := msgpack.NewReader(r)
+	c := &Container{}
+	if err := c.Header.DecodeMsgpack(mr); err != nil {
+		return nil, errors.Newf("invalid file header: %v", err)
+	}
+
+	hdr := c.Header
+	switch {
+	case hdr.SchemaVersion < 1:
+		return nil, errors.Newf("invalid schema version %d", hdr.SchemaVersion)
+	case hdr.SchemaVersion > SchemaVersion:
+		return nil, errors.Newf("unsupported schema version %d (written by lxnc %s, supported up to version %d)", hdr.SchemaVersion, hdr.LxncVersion, SchemaVersion)
+	}
+
+	var err error
+	switch hdr.Kind {
+	case KindCatalog:
+		c.Catalog = &Catalog{}
+		err = c.Catalog.DecodeMsgpack(mr)
+	case KindDictionary:
+		c.Dictionary = &Dictionary{}
+		err = c.Dictionary.DecodeMsgpack(mr)
+	default:
+		return nil, errors.Newf("unknown container kind %d", int(hdr.Kind))
+	}
+	if err != nil {
+		return nil, errors.Newf("invalid %s: %v", hdr.Kind, err)
+	}
+	return c, nil
+}
+
+// ReadCatalog reads an .lxnc file which contains a catalog. See ReadContainer
+// for the compatibility checks.
+func ReadCatalog(r io.Reader) (*Catalog, Header, error) {
+	c, err := readContainerKind(r, KindCatalog)
+	if err != nil {
+		return nil, Header{}, err
+	}
+	return c.Catalog, c.Header, nil
+}
+
+// ReadDictionary reads an .lxnc file which contains a dictionary. See
+// ReadContainer for the compatibility checks.
+func ReadDictionary(r io.Reader) (*Dictionary, Header, error) {
+	c, err := readContainerKind(r, KindDictionary)
+	if err != nil {
+		return nil, Header{}, err
+	}
+	return c.Dictionary, c.Header, nil
+}
+
+func readContainerKind(r io.Reader, kind ContainerKind) (*Container, error) {
+	c, err := ReadContainer(r)
+	switch {
+	case err != nil:
+		return nil, err
+	case c.Header.Kind != kind:
+		return nil, errors.Newf("expected a %s, got a %s", kind, c.Header.Kind)
+	}
+	return c, nil
+}
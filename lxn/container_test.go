@@ -0,0 +1,134 @@
+package lxn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	msgpack "github.com/mprot/msgpack-go"
+
+	"github.com/liblxn/lxnc/locale"
+)
+
+func TestContainerCatalog(t *testing.T) {
+	cat := &Catalog{
+		LocaleID: "de",
+		Messages: []Message{
+			{Key: "greeting", Text: []string{"Hallo"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCatalog(&buf, cat, "1.2.3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte(ContainerMagic)) {
+		t.Fatalf("missing magic bytes: %q", buf.Bytes())
+	}
+
+	c, err := ReadContainer(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedHeader := Header{
+		SchemaVersion: SchemaVersion,
+		Kind:          KindCatalog,
+		LxncVersion:   "1.2.3",
+		CLDRVersion:   locale.CLDRVersion,
+	}
+	switch {
+	case c.Header != expectedHeader:
+		t.Errorf("unexpected header: %+v", c.Header)
+	case c.Dictionary != nil:
+		t.Errorf("unexpected dictionary: %+v", c.Dictionary)
+	case !reflect.DeepEqual(c.Catalog, cat):
+		t.Errorf("unexpected catalog: %+v", c.Catalog)
+	}
+
+	if _, _, err := ReadDictionary(bytes.NewReader(buf.Bytes())); err == nil || err.Error() != "expected a dictionary, got a catalog" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestContainerDictionary(t *testing.T) {
+	loc, err := locale.New("de")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dic := NewDictionary(loc, []Message{{Key: "greeting", Text: []string{"Hallo"}}})
+
+	var buf bytes.Buffer
+	if err := WriteDictionary(&buf, dic, "1.2.3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoded, hdr, err := ReadDictionary(bytes.NewReader(buf.Bytes()))
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case hdr.Kind != KindDictionary:
+		t.Errorf("unexpected kind: %s", hdr.Kind)
+	case decoded.Locale.ID != "de" || !reflect.DeepEqual(decoded.Messages, dic.Messages):
+		t.Errorf("unexpected dictionary: %+v", decoded)
+	}
+
+	if _, _, err := ReadCatalog(bytes.NewReader(buf.Bytes())); err == nil || err.Error() != "expected a catalog, got a dictionary" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestContainerCompatibility(t *testing.T) {
+	container := func(hdr Header) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(ContainerMagic)
+		if err := msgpack.Encode(&buf, hdr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := msgpack.Encode(&buf, Catalog{LocaleID: "de"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	var legacy bytes.Buffer
+	if err := msgpack.Encode(&legacy, Catalog{LocaleID: "de"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		data []byte
+		err  string
+	}{
+		{
+			data: legacy.Bytes(),
+			err:  "not an lxnc file (missing file header, the file may have been written by an older version of lxnc)",
+		},
+		{
+			data: []byte("LX"),
+			err:  "not an lxnc file (missing file header, the file may have been written by an older version of lxnc)",
+		},
+		{
+			data: container(Header{SchemaVersion: SchemaVersion + 1, Kind: KindCatalog, LxncVersion: "9.0.0"}),
+			err:  "unsupported schema version 2 (written by lxnc 9.0.0, supported up to version 1)",
+		},
+		{
+			data: container(Header{SchemaVersion: 0, Kind: KindCatalog}),
+			err:  "invalid schema version 0",
+		},
+		{
+			data: container(Header{SchemaVersion: SchemaVersion, Kind: 7}),
+			err:  "unknown container kind 7",
+		},
+	}
+
+	for i, test := range tests {
+		_, err := ReadContainer(bytes.NewReader(test.data))
+		switch {
+		case err == nil:
+			t.Errorf("expected error for test %d", i)
+		case err.Error() != test.err:
+			t.Errorf("unexpected error for test %d: %v", i, err)
+		}
+	}
+}
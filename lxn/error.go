@@ -1,16 +1,35 @@
 package lxn
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-// Error represents a parsing error with an additional error position.
+// Error represents a parsing error with an additional error position. The
+// error spans the source from Pos up to End. If End is not set, the error
+// only points to Pos.
 type Error struct {
-	Err error // underlying error
-	Pos Pos   // position where the error occured
+	Err        error  // underlying error
+	Pos        Pos    // position where the error occured
+	End        Pos    // position right after the offending text
+	Suggestion string // optional hint how to fix the error, e.g. "did you mean .other?"
+	Includes   []Pos  // positions of the include directives which led to the file, innermost first
 }
 
 // Error implements the error interface and returns the error message.
 func (e Error) Error() string {
-	return e.Pos.String() + ": " + e.Err.Error()
+	msg := e.Pos.String() + ": " + e.Err.Error()
+	if e.Suggestion != "" {
+		msg += " (" + e.Suggestion + ")"
+	}
+	if len(e.Includes) != 0 {
+		includes := make([]string, 0, len(e.Includes))
+		for _, pos := range e.Includes {
+			includes = append(includes, pos.String())
+		}
+		msg += " (included from " + strings.Join(includes, ", ") + ")"
+	}
+	return msg
 }
 
 // ErrorList holds multiple parsing errors.
@@ -36,9 +55,14 @@ func (e ErrorList) err() error {
 }
 
 func (e *ErrorList) add(err error, pos Pos) {
+	e.addSpan(err, pos, pos)
+}
+
+func (e *ErrorList) addSpan(err error, start Pos, end Pos) {
 	*e = append(*e, Error{
 		Err: err,
-		Pos: pos,
+		Pos: start,
+		End: end,
 	})
 }
 
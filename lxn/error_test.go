@@ -29,6 +29,21 @@ func TestError(t *testing.T) {
 	}
 }
 
+func TestErrorWithIncludes(t *testing.T) {
+	err := &Error{
+		Err: errors.New("foobar"),
+		Pos: Pos{File: "c.lxn", Line: 2, Column: 4},
+		Includes: []Pos{
+			{File: "b.lxn", Line: 1, Column: 16},
+			{File: "a.lxn", Line: 3, Column: 16},
+		},
+	}
+
+	if msg := err.Error(); msg != "c.lxn:2:4: foobar (included from b.lxn:1:16, a.lxn:3:16)" {
+		t.Errorf("unexpected error message: %q", msg)
+	}
+}
+
 func TestErrorList(t *testing.T) {
 	var errs ErrorList
 
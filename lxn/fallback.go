@@ -0,0 +1,65 @@
+package lxn
+
+// FallbackSource holds the messages which are defined for a single locale
+// of a fallback chain.
+type FallbackSource struct {
+	LocaleID string
+	Messages []Message
+}
+
+// InheritedMessage describes a message which is missing in the target locale
+// and was taken from one of its ancestors.
+type InheritedMessage struct {
+	Section  string
+	Key      string
+	LocaleID string // locale the message was inherited from
+}
+
+// ResolveFallbacks merges the messages of a locale's fallback chain. The first
+// source holds the messages of the target locale, the following sources hold
+// the messages of its ancestors, nearest ancestor first (e.g. de-AT, de, root).
+// Each message which is missing in the target locale is filled from the nearest
+// ancestor that defines it. The origin of every returned message is set to the
+// locale the message came from.
+//
+// The returned list of inherited messages contains an entry for each message
+// which was not defined by the target locale.
+func ResolveFallbacks(sources []FallbackSource) ([]Message, []InheritedMessage) {
+	type messageID struct {
+		section string
+		key     string
+	}
+
+	var (
+		messages  []Message
+		inherited []InheritedMessage
+	)
+	defined := make(map[messageID]struct{})
+	for i, src := range sources {
+		added := make(map[messageID]struct{})
+		for _, msg := range src.Messages {
+			id := messageID{section: msg.Section, key: msg.Key}
+			if _, has := defined[id]; has {
+				continue
+			}
+
+			msg.Origin = src.LocaleID
+			messages = append(messages, msg)
+			added[id] = struct{}{}
+			if i != 0 {
+				inherited = append(inherited, InheritedMessage{
+					Section:  msg.Section,
+					Key:      msg.Key,
+					LocaleID: src.LocaleID,
+				})
+			}
+		}
+
+		// Duplicates within a single source are kept, so ValidateMessages
+		// is still able to report them.
+		for id := range added {
+			defined[id] = struct{}{}
+		}
+	}
+	return messages, inherited
+}
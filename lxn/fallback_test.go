@@ -0,0 +1,78 @@
+package lxn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveFallbacks(t *testing.T) {
+	sources := []FallbackSource{
+		{
+			LocaleID: "de-AT",
+			Messages: []Message{
+				{Key: "greeting", Text: []string{"Servus"}},
+				{Section: "cart", Key: "empty", Text: []string{"Der Einkaufswagen ist leer"}},
+			},
+		},
+		{
+			LocaleID: "de",
+			Messages: []Message{
+				{Key: "greeting", Text: []string{"Hallo"}},
+				{Key: "farewell", Text: []string{"Tschüss"}},
+				{Section: "cart", Key: "empty", Text: []string{"Der Warenkorb ist leer"}},
+			},
+		},
+		{
+			LocaleID: "und",
+			Messages: []Message{
+				{Key: "farewell", Text: []string{"Bye"}},
+				{Section: "cart", Key: "checkout", Text: []string{"Checkout"}},
+			},
+		},
+	}
+
+	expectedMessages := []Message{
+		{Key: "greeting", Text: []string{"Servus"}, Origin: "de-AT"},
+		{Section: "cart", Key: "empty", Text: []string{"Der Einkaufswagen ist leer"}, Origin: "de-AT"},
+		{Key: "farewell", Text: []string{"Tschüss"}, Origin: "de"},
+		{Section: "cart", Key: "checkout", Text: []string{"Checkout"}, Origin: "und"},
+	}
+	expectedInherited := []InheritedMessage{
+		{Key: "farewell", LocaleID: "de"},
+		{Section: "cart", Key: "checkout", LocaleID: "und"},
+	}
+
+	messages, inherited := ResolveFallbacks(sources)
+	if !reflect.DeepEqual(messages, expectedMessages) {
+		t.Errorf("unexpected messages: %+v", messages)
+	}
+	if !reflect.DeepEqual(inherited, expectedInherited) {
+		t.Errorf("unexpected inherited messages: %+v", inherited)
+	}
+}
+
+func TestResolveFallbacksWithDuplicates(t *testing.T) {
+	sources := []FallbackSource{
+		{
+			LocaleID: "de",
+			Messages: []Message{
+				{Key: "key", Text: []string{"eins"}},
+				{Key: "key", Text: []string{"zwei"}},
+			},
+		},
+		{
+			LocaleID: "und",
+			Messages: []Message{
+				{Key: "key", Text: []string{"one"}},
+			},
+		},
+	}
+
+	messages, inherited := ResolveFallbacks(sources)
+	switch {
+	case len(messages) != 2:
+		t.Errorf("unexpected number of messages: %d", len(messages))
+	case len(inherited) != 0:
+		t.Errorf("unexpected inherited messages: %+v", inherited)
+	}
+}
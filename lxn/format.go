@@ -0,0 +1,85 @@
+package lxn
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// Format formats the source of an lxn file into canonical form. Besides the
+// canonical layout of PrintFile, the replacements are normalized: the types and
+// the option names of plurals, plural ranges, money and select replacements are
+// lowercased, and the options of plurals and plural ranges are ordered by their
+// plural category. Comments are preserved.
+//
+// If the source contains syntax errors, it is not formatted and the errors are
+// returned.
+func Format(filename string, src []byte) ([]byte, error) {
+	file, err := ParseFile(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, decl := range file.Decls {
+		if msg, ok := decl.(*MessageDecl); ok {
+			normalizeFragments(msg.Body)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := PrintFile(&buf, file); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func normalizeFragments(fragments []Fragment) {
+	for _, fragment := range fragments {
+		if expr, ok := fragment.(*ReplacementExpr); ok {
+			normalizeReplacementExpr(expr)
+		}
+	}
+}
+
+func normalizeReplacementExpr(expr *ReplacementExpr) {
+	for _, opt := range expr.Options {
+		normalizeFragments(opt.Value)
+	}
+
+	expr.Type = strings.ToLower(expr.Type)
+	switch expr.Type {
+	case "plural", "pluralrange":
+		lowercaseOptions(expr.Options)
+		sort.SliceStable(expr.Options, func(i, j int) bool {
+			return pluralOptionRank(expr.Options[i].Name) < pluralOptionRank(expr.Options[j].Name)
+		})
+	case "money", "select":
+		lowercaseOptions(expr.Options)
+	}
+}
+
+func lowercaseOptions(options []*Option) {
+	for _, opt := range options {
+		if !strings.HasPrefix(opt.Name, "[") {
+			opt.Name = strings.ToLower(opt.Name)
+		}
+	}
+}
+
+// pluralOptionRank returns the rank of a plural option in canonical order: the
+// plural type, the offset or end of a range, the custom values, and the plural
+// categories from zero to other. Unknown options go last.
+func pluralOptionRank(name string) int {
+	switch {
+	case name == "cardinal" || name == "ordinal":
+		return 0
+	case name == "offset" || name == "to":
+		return 1
+	case strings.HasPrefix(name, "["):
+		return 2
+	}
+	if cat, ok := pluralCategoryOf(name); ok {
+		return 3 + int(cat)
+	}
+	return 3 + len(pluralCategoryNames)
+}
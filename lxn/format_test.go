@@ -0,0 +1,63 @@
+package lxn
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	const input = `// comment
+[[ section ]]
+key:
+    ${count:plural .OTHER{many}  .[7]{seven} .One{one} .ordinal .offset{1}}
+    ${amount:money .CURRENCY{EUR}} ${g:select .[Male]{he} .Default{Male}}
+range:  ${from:PluralRange .other{days} .TO{${to}}}
+`
+
+	const expected = `// comment
+[[section]]
+key:
+	${count:plural .ordinal .offset{1} .[7]{seven} .one{one} .other{many}}
+	${amount:money .currency{EUR}} ${g:select .[Male]{he} .default{Male}}
+range: ${from:pluralrange .to{${to}} .other{days}}
+`
+
+	formatted, err := Format("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(formatted) != expected {
+		t.Errorf("unexpected output:\n%s", formatted)
+	}
+
+	if _, err := Format("test", []byte("key: ${foo\n")); err == nil {
+		t.Error("expected syntax error")
+	}
+}
+
+func TestFormatIdempotent(t *testing.T) {
+	inputs := []string{
+		"key: text\n \t",
+		"key: text\n  \n\n",
+		"key: text\n\n  ",
+		"key:\n  \t",
+		"key: text\r\n  \r\n \t",
+		"key:\n  first\n  second\n  ",
+		"key: |\n  verbatim\n\n  \n \t",
+		"key: ${foo:select .a{x}\n  }\n  ",
+		"// comment\n  ",
+		"[[section]]\n  \t",
+	}
+
+	for _, input := range inputs {
+		formatted, err := Format("test", []byte(input))
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", input, err)
+			continue
+		}
+		reformatted, err := Format("test", formatted)
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error for %q: %v", formatted, err)
+		case string(reformatted) != string(formatted):
+			t.Errorf("formatting %q is not idempotent: %q, then %q", input, formatted, reformatted)
+		}
+	}
+}
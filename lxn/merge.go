@@ -0,0 +1,151 @@
+package lxn
+
+import (
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/errors"
+)
+
+// ConflictPolicy defines how MergeCatalogs resolves messages which are defined
+// by more than one source.
+type ConflictPolicy int
+
+const (
+	// ConflictError reports an error for each conflicting message.
+	ConflictError ConflictPolicy = iota
+	// ConflictFirstWins keeps the message which was defined first.
+	ConflictFirstWins
+	// ConflictLastWins keeps the message which was defined last.
+	ConflictLastWins
+	// ConflictPreferFileOrder keeps the message of the source with the lowest
+	// priority value. Sources with the same priority are resolved like
+	// ConflictFirstWins.
+	ConflictPreferFileOrder
+)
+
+var conflictPolicyNames = []string{
+	ConflictError:           "error",
+	ConflictFirstWins:       "first-wins",
+	ConflictLastWins:        "last-wins",
+	ConflictPreferFileOrder: "prefer-by-file-order",
+}
+
+// ParseConflictPolicy returns the conflict policy with the given name, which is
+// one of 'error', 'first-wins', 'last-wins' and 'prefer-by-file-order'.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	for policy, s := range conflictPolicyNames {
+		if strings.EqualFold(name, s) {
+			return ConflictPolicy(policy), nil
+		}
+	}
+	return 0, errors.Newf("unknown conflict policy %q (expected %s)", name, strings.Join(conflictPolicyNames, ", "))
+}
+
+func (p ConflictPolicy) String() string {
+	if p < 0 || int(p) >= len(conflictPolicyNames) {
+		return "unknown"
+	}
+	return conflictPolicyNames[p]
+}
+
+// MergeSource holds the messages of a single input of MergeCatalogs, e.g. the
+// messages of a catalog file.
+type MergeSource struct {
+	Name      string // name of the input, e.g. the file name
+	Namespace string // section prefix for all messages, may be empty
+	Priority  int    // lower values win for ConflictPreferFileOrder
+	Messages  []Message
+}
+
+// OverriddenMessage describes a message which was dropped by MergeCatalogs in
+// favor of another message with the same section and key.
+type OverriddenMessage struct {
+	Section string
+	Key     string
+	Source  string // name of the source the dropped message came from
+	By      string // name of the source the kept message came from
+}
+
+// MergeCatalogs merges the messages of the sources in the given order. If a
+// source has a namespace, the namespace is prepended to the section of each of
+// its messages (e.g. 'shop' and 'cart' yield 'shop.cart'). Messages with the
+// same section and key are resolved with the conflict policy, which also
+// applies to duplicates within a single source. The kept message takes the
+// place of the first message with its section and key.
+//
+// The returned list of overridden messages contains an entry for each dropped
+// message. If the policy is ConflictError, an ErrorList with an error for each
+// conflicting message is returned instead.
+func MergeCatalogs(sources []MergeSource, policy ConflictPolicy) ([]Message, []OverriddenMessage, error) {
+	type messageID struct {
+		section string
+		key     string
+	}
+	type entry struct {
+		msg    Message
+		source int
+	}
+
+	var (
+		entries    []entry
+		overridden []OverriddenMessage
+		errs       ErrorList
+	)
+	indices := make(map[messageID]int) // message id => index in entries
+	for i, src := range sources {
+		for _, msg := range src.Messages {
+			if src.Namespace != "" {
+				if msg.Section == "" {
+					msg.Section = src.Namespace
+				} else {
+					msg.Section = src.Namespace + "." + msg.Section
+				}
+			}
+
+			id := messageID{section: msg.Section, key: msg.Key}
+			idx, has := indices[id]
+			if !has {
+				indices[id] = len(entries)
+				entries = append(entries, entry{msg: msg, source: i})
+				continue
+			}
+
+			kept := entries[idx]
+			replace := false
+			switch policy {
+			case ConflictError:
+				errs = append(errs, errors.Newf("message %s in %s is already defined in %s", MessageName(msg.Section, msg.Key), src.Name, sources[kept.source].Name))
+				continue
+			case ConflictLastWins:
+				replace = true
+			case ConflictPreferFileOrder:
+				replace = src.Priority < sources[kept.source].Priority
+			}
+
+			if replace {
+				entries[idx] = entry{msg: msg, source: i}
+				overridden = append(overridden, OverriddenMessage{Section: msg.Section, Key: msg.Key, Source: sources[kept.source].Name, By: src.Name})
+			} else {
+				overridden = append(overridden, OverriddenMessage{Section: msg.Section, Key: msg.Key, Source: src.Name, By: sources[kept.source].Name})
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return nil, nil, errs
+	}
+
+	messages := make([]Message, 0, len(entries))
+	for _, e := range entries {
+		messages = append(messages, e.msg)
+	}
+	return messages, overridden, nil
+}
+
+// MessageName returns the qualified name of a message, e.g. 'cart.empty'. The
+// name of a message without a section is its key.
+func MessageName(section string, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + "." + key
+}
@@ -0,0 +1,166 @@
+package lxn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mergeTestSources() []MergeSource {
+	return []MergeSource{
+		{
+			Name:     "app.lxnc",
+			Priority: 1,
+			Messages: []Message{
+				{Key: "greeting", Text: []string{"Hallo"}},
+				{Section: "cart", Key: "empty", Text: []string{"Der Warenkorb ist leer"}},
+			},
+		},
+		{
+			Name:     "shop.lxnc",
+			Priority: 0,
+			Messages: []Message{
+				{Section: "cart", Key: "empty", Text: []string{"Der Einkaufswagen ist leer"}},
+				{Section: "cart", Key: "checkout", Text: []string{"Zur Kasse"}},
+			},
+		},
+	}
+}
+
+func TestMergeCatalogs(t *testing.T) {
+	tests := []struct {
+		policy             ConflictPolicy
+		expectedMessages   []Message
+		expectedOverridden []OverriddenMessage
+	}{
+		{
+			policy: ConflictFirstWins,
+			expectedMessages: []Message{
+				{Key: "greeting", Text: []string{"Hallo"}},
+				{Section: "cart", Key: "empty", Text: []string{"Der Warenkorb ist leer"}},
+				{Section: "cart", Key: "checkout", Text: []string{"Zur Kasse"}},
+			},
+			expectedOverridden: []OverriddenMessage{
+				{Section: "cart", Key: "empty", Source: "shop.lxnc", By: "app.lxnc"},
+			},
+		},
+		{
+			policy: ConflictLastWins,
+			expectedMessages: []Message{
+				{Key: "greeting", Text: []string{"Hallo"}},
+				{Section: "cart", Key: "empty", Text: []string{"Der Einkaufswagen ist leer"}},
+				{Section: "cart", Key: "checkout", Text: []string{"Zur Kasse"}},
+			},
+			expectedOverridden: []OverriddenMessage{
+				{Section: "cart", Key: "empty", Source: "app.lxnc", By: "shop.lxnc"},
+			},
+		},
+		{
+			policy: ConflictPreferFileOrder,
+			expectedMessages: []Message{
+				{Key: "greeting", Text: []string{"Hallo"}},
+				{Section: "cart", Key: "empty", Text: []string{"Der Einkaufswagen ist leer"}},
+				{Section: "cart", Key: "checkout", Text: []string{"Zur Kasse"}},
+			},
+			expectedOverridden: []OverriddenMessage{
+				{Section: "cart", Key: "empty", Source: "app.lxnc", By: "shop.lxnc"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		messages, overridden, err := MergeCatalogs(mergeTestSources(), test.policy)
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error for %s: %v", test.policy, err)
+		case !reflect.DeepEqual(messages, test.expectedMessages):
+			t.Errorf("unexpected messages for %s: %+v", test.policy, messages)
+		case !reflect.DeepEqual(overridden, test.expectedOverridden):
+			t.Errorf("unexpected overridden messages for %s: %+v", test.policy, overridden)
+		}
+	}
+}
+
+func TestMergeCatalogsWithError(t *testing.T) {
+	sources := mergeTestSources()
+	sources[1].Messages = append(sources[1].Messages, Message{Key: "greeting", Text: []string{"Servus"}})
+
+	_, _, err := MergeCatalogs(sources, ConflictError)
+	errs, ok := err.(ErrorList)
+	switch {
+	case !ok:
+		t.Fatalf("expected error list, got %v", err)
+	case len(errs) != 2:
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+
+	expected := []string{
+		"message cart.empty in shop.lxnc is already defined in app.lxnc",
+		"message greeting in shop.lxnc is already defined in app.lxnc",
+	}
+	for i, msg := range expected {
+		if errs[i].Error() != msg {
+			t.Errorf("unexpected error %d: %v", i, errs[i])
+		}
+	}
+}
+
+func TestMergeCatalogsWithNamespaces(t *testing.T) {
+	sources := mergeTestSources()
+	sources[0].Namespace = "app"
+	sources[1].Namespace = "shop"
+
+	expectedMessages := []Message{
+		{Section: "app", Key: "greeting", Text: []string{"Hallo"}},
+		{Section: "app.cart", Key: "empty", Text: []string{"Der Warenkorb ist leer"}},
+		{Section: "shop.cart", Key: "empty", Text: []string{"Der Einkaufswagen ist leer"}},
+		{Section: "shop.cart", Key: "checkout", Text: []string{"Zur Kasse"}},
+	}
+
+	messages, overridden, err := MergeCatalogs(sources, ConflictError)
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case !reflect.DeepEqual(messages, expectedMessages):
+		t.Errorf("unexpected messages: %+v", messages)
+	case len(overridden) != 0:
+		t.Errorf("unexpected overridden messages: %+v", overridden)
+	}
+}
+
+func TestMergeCatalogsWithDuplicates(t *testing.T) {
+	sources := []MergeSource{
+		{
+			Name: "app.lxnc",
+			Messages: []Message{
+				{Key: "key", Text: []string{"eins"}},
+				{Key: "key", Text: []string{"zwei"}},
+			},
+		},
+	}
+
+	messages, overridden, err := MergeCatalogs(sources, ConflictLastWins)
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case !reflect.DeepEqual(messages, []Message{{Key: "key", Text: []string{"zwei"}}}):
+		t.Errorf("unexpected messages: %+v", messages)
+	case !reflect.DeepEqual(overridden, []OverriddenMessage{{Key: "key", Source: "app.lxnc", By: "app.lxnc"}}):
+		t.Errorf("unexpected overridden messages: %+v", overridden)
+	}
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, policy := range []ConflictPolicy{ConflictError, ConflictFirstWins, ConflictLastWins, ConflictPreferFileOrder} {
+		p, err := ParseConflictPolicy(policy.String())
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error for %s: %v", policy, err)
+		case p != policy:
+			t.Errorf("unexpected policy for %s: %s", policy, p)
+		}
+	}
+
+	if _, err := ParseConflictPolicy("newest"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
@@ -0,0 +1,61 @@
+package lxn
+
+import "unicode/utf8"
+
+// StripNotes removes the translator notes from all messages. Notes are only
+// meant for translators, so they should not be shipped with the compiled
+// messages.
+func StripNotes(messages []Message) {
+	for i := range messages {
+		messages[i].Notes = Notes{}
+	}
+}
+
+// maxTextLength returns the maximum number of characters the static text of a
+// message can have. For plurals, plural ranges, and selects the longest variant
+// is taken into account. The values of all other replacements are unknown at
+// compile time and do not count.
+func maxTextLength(msg Message) int {
+	n := 0
+	for _, text := range msg.Text {
+		n += utf8.RuneCountInString(text)
+	}
+
+	longest := func(variants map[PluralCategory]Message) int {
+		max := 0
+		for _, variant := range variants {
+			if l := maxTextLength(variant); l > max {
+				max = l
+			}
+		}
+		return max
+	}
+
+	for _, repl := range msg.Replacements {
+		max := 0
+		switch details := repl.Details.Value.(type) {
+		case PluralDetails:
+			max = longest(details.Variants)
+			for _, variant := range details.Custom {
+				if l := maxTextLength(variant); l > max {
+					max = l
+				}
+			}
+			for _, variant := range details.CustomDecimals {
+				if l := maxTextLength(variant); l > max {
+					max = l
+				}
+			}
+		case PluralRangeDetails:
+			max = longest(details.Variants)
+		case SelectDetails:
+			for _, variant := range details.Cases {
+				if l := maxTextLength(variant); l > max {
+					max = l
+				}
+			}
+		}
+		n += max
+	}
+	return n
+}
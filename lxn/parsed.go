@@ -0,0 +1,71 @@
+package lxn
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+)
+
+// Parsed messages may contain unresolved message references, which cannot be
+// encoded into a catalog or a dictionary. They are encoded with gob instead,
+// which keeps the references including their positions.
+
+func init() {
+	gob.Register(EmptyDetails{})
+	gob.Register(MoneyDetails{})
+	gob.Register(PluralDetails{})
+	gob.Register(SelectDetails{})
+	gob.Register(PluralRangeDetails{})
+	gob.Register(MarkupDetails{})
+	gob.Register(referenceDetails{})
+}
+
+// EncodeParsedMessages writes the messages returned by ParseMessages or
+// ParseSource to w, e.g. to cache them. In contrast to the encoding of a
+// catalog, the messages may contain unresolved message references.
+func EncodeParsedMessages(w io.Writer, msgs []Message) error {
+	return gob.NewEncoder(w).Encode(msgs)
+}
+
+// DecodeParsedMessages reads messages which were written by
+// EncodeParsedMessages.
+func DecodeParsedMessages(r io.Reader) ([]Message, error) {
+	var msgs []Message
+	if err := gob.NewDecoder(r).Decode(&msgs); err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// GobEncode implements the gob.GobEncoder interface, since gob does not support
+// structs without fields.
+func (EmptyDetails) GobEncode() ([]byte, error) {
+	return nil, nil
+}
+
+// GobDecode implements the gob.GobDecoder interface.
+func (*EmptyDetails) GobDecode([]byte) error {
+	return nil
+}
+
+type gobReference struct {
+	Section string
+	Key     string
+	Pos     Pos
+	End     Pos
+}
+
+func (d referenceDetails) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(gobReference{Section: d.section, Key: d.key, Pos: d.pos, End: d.end})
+	return buf.Bytes(), err
+}
+
+func (d *referenceDetails) GobDecode(data []byte) error {
+	var ref gobReference
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&ref); err != nil {
+		return err
+	}
+	*d = referenceDetails{section: ref.Section, key: ref.Key, pos: ref.Pos, end: ref.End}
+	return nil
+}
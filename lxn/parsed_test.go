@@ -0,0 +1,59 @@
+package lxn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	msgpack "github.com/mprot/msgpack-go"
+)
+
+func TestEncodeParsedMessages(t *testing.T) {
+	input := benchmarkInput("section", 3)
+	input = append(input, "unresolved: ${@missing.key}\n"...)
+
+	var p parser
+	msgs, err := p.Parse("test.lxn", input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeParsedMessages(&buf, msgs); err != nil {
+		t.Fatalf("unexpected encoding error: %v", err)
+	}
+	decoded, err := DecodeParsedMessages(&buf)
+	if err != nil {
+		t.Fatalf("unexpected decoding error: %v", err)
+	}
+
+	// The references are kept, so resolving yields the same errors.
+	err = ResolveReferences(msgs)
+	decodedErr := ResolveReferences(decoded)
+	if err == nil || decodedErr == nil || err.Error() != decodedErr.Error() {
+		t.Fatalf("unexpected resolve errors: %v, %v", err, decodedErr)
+	}
+	if decodedErr.(ErrorList)[0].(Error).End != err.(ErrorList)[0].(Error).End {
+		t.Errorf("unexpected reference span: %v", decodedErr.(ErrorList)[0].(Error).End)
+	}
+
+	// The catalogs are the same after a round trip. Maps are encoded in random
+	// order, so the decoded catalogs are compared.
+	msgs, decoded = msgs[:len(msgs)-1], decoded[:len(decoded)-1]
+	var expected, actual Catalog
+	roundTrip(t, &Catalog{LocaleID: "en", Messages: msgs}, &expected)
+	roundTrip(t, &Catalog{LocaleID: "en", Messages: decoded}, &actual)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("decoded messages differ")
+	}
+}
+
+func roundTrip(t *testing.T, cat *Catalog, decoded *Catalog) {
+	var buf bytes.Buffer
+	if err := msgpack.Encode(&buf, cat); err != nil {
+		t.Fatal(err)
+	}
+	if err := msgpack.Decode(&buf, decoded); err != nil {
+		t.Fatal(err)
+	}
+}
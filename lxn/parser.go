@@ -1,6 +1,8 @@
 package lxn
 
 import (
+	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"unicode"
@@ -8,46 +10,177 @@ import (
 	"github.com/liblxn/lxnc/internal/errors"
 )
 
+// parser lowers the syntax tree of lxn files into messages. It validates the
+// replacements and resolves the include directives.
 type parser struct {
-	t      tokenizer
-	tokens <-chan token
-	tok    token
-	errs   ErrorList
+	errs    ErrorList
+	section string   // current section
+	plurals []string // keys of the enclosing plural replacements
+
+	// Included files are read with readFile. If readFile is nil, include
+	// directives are not supported. If slashPaths is set, the file names are
+	// slash-separated paths as used by io/fs instead of operating system paths.
+	readFile   func(filename string) ([]byte, error)
+	slashPaths bool
+	files      []string // chain of files which are currently parsed, outermost first
+	includes   []Pos    // positions of the include directives in the chain, outermost first
+	included   []string // all files which were reached by include directives
 }
 
 func (p *parser) Parse(filename string, input []byte) ([]Message, error) {
-	p.tokens = p.t.Scan(filename, input)
+	p.files = append(p.files[:0], filename)
+	p.includes = p.includes[:0]
+	p.included = p.included[:0]
+	return p.parse(filename, input)
+}
+
+func (p *parser) parse(filename string, input []byte) ([]Message, error) {
 	p.errs.clear()
-	p.next() // scan initial token
-
-	var m []Message
-	section := ""
-	for {
-		switch p.tok.typ {
-		case eof:
-			return m, p.errs.err()
-		case invalid:
-			p.next()
-		case sectionHeader:
-			section = p.tok.val
-			p.next()
-		case messageKey:
-			m = append(m, p.parseMessage(section))
+	p.section = ""
+	p.plurals = p.plurals[:0]
+
+	file, err := ParseFile(filename, input)
+	if errs, ok := err.(ErrorList); ok {
+		for _, e := range errs {
+			p.report(e.(Error))
+		}
+	}
+
+	var (
+		m     []Message
+		notes Notes // notes for the next message
+	)
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *Comment:
+			if decl.Doc {
+				p.parseDocComment(decl, &notes)
+			}
+		case *SectionDecl:
+			p.section = decl.Name
+			notes = Notes{}
+		case *IncludeDecl:
+			m = append(m, p.parseInclude(decl)...)
+			notes = Notes{}
+		case *MessageDecl:
+			msg := p.parseMessage(decl)
+			msg.Notes = notes
+			m = append(m, msg)
+			notes = Notes{}
+		}
+	}
+	return m, p.errs.err()
+}
+
+// parseDocComment adds a doc comment line to the notes of the next message. A line
+// can either be an annotation (@desc, @context, or @maxlen) or a plain text which
+// is added to the description.
+func (p *parser) parseDocComment(c *Comment, notes *Notes) {
+	appendNote := func(note string, text string) string {
+		switch {
+		case text == "":
+			return note
+		case note == "":
+			return text
 		default:
-			p.errorf("unexpected token %q", p.tok.val)
-			p.next()
+			return note + " " + text
 		}
 	}
+
+	line := strings.TrimSpace(c.Text)
+	if !strings.HasPrefix(line, "@") {
+		notes.Description = appendNote(notes.Description, line)
+		return
+	}
+
+	name, value := line, ""
+	if idx := strings.IndexFunc(line, unicode.IsSpace); idx >= 0 {
+		name, value = line[:idx], strings.TrimSpace(line[idx:])
+	}
+
+	switch name {
+	case "@desc":
+		notes.Description = appendNote(notes.Description, value)
+	case "@context":
+		notes.Context = appendNote(notes.Context, value)
+	case "@maxlen":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			p.errorf(c, "invalid @maxlen value: %q", value)
+			return
+		}
+		notes.MaxLength = n
+	default:
+		p.errorf(c, "unknown annotation: %s", name)
+	}
+}
+
+// parseInclude parses the file of an include directive and returns its messages.
+// The path is resolved relative to the including file. An included file always
+// starts without a section, and the sections declared in the included file do not
+// affect the including file.
+func (p *parser) parseInclude(decl *IncludeDecl) []Message {
+	if decl.Path == "" {
+		p.errorf(decl, "empty include path")
+		return nil
+	}
+	if p.readFile == nil {
+		p.errorf(decl, "include directives not supported")
+		return nil
+	}
+
+	filename, clean := p.includePath(decl.Path), filepath.Clean
+	if p.slashPaths {
+		clean = path.Clean
+	}
+	for i, f := range p.files {
+		if clean(f) == filename {
+			cycle := append(p.files[i:len(p.files):len(p.files)], filename)
+			p.errorf(decl, "include cycle: %s", strings.Join(cycle, " -> "))
+			return nil
+		}
+	}
+
+	input, err := p.readFile(filename)
+	if err != nil {
+		p.errorf(decl, "%v", err)
+		return nil
+	}
+
+	child := parser{
+		readFile:   p.readFile,
+		slashPaths: p.slashPaths,
+		files:      append(p.files[:len(p.files):len(p.files)], filename),
+		includes:   append(p.includes[:len(p.includes):len(p.includes)], decl.Start()),
+	}
+	msgs, _ := child.parse(filename, input)
+	p.errs = append(p.errs, child.errs...)
+	p.included = append(append(p.included, filename), child.included...)
+	return msgs
 }
 
-func (p *parser) parseMessage(section string) (msg Message) {
-	msg.Section = section
-	msg.Key = p.tok.val
-	p.next()
-	return p.parseMessageFragments(msg)
+// includePath resolves the path of an include directive relative to the file
+// which is currently parsed.
+func (p *parser) includePath(includePath string) string {
+	current := p.files[len(p.files)-1]
+	if p.slashPaths {
+		return path.Join(path.Dir(current), includePath)
+	}
+
+	filename := filepath.FromSlash(includePath)
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(filepath.Dir(current), filename)
+	}
+	return filename
+}
+
+func (p *parser) parseMessage(decl *MessageDecl) (msg Message) {
+	msg.Section = p.section
+	msg.Key = decl.Key
+	return p.parseMessageFragments(msg, decl.Body)
 }
 
-func (p *parser) parseMessageFragments(msg Message) Message {
+func (p *parser) parseMessageFragments(msg Message, fragments []Fragment) Message {
 	defer func() {
 		// trim trailing whitespaces
 		ntext := len(msg.Text)
@@ -72,220 +205,362 @@ func (p *parser) parseMessageFragments(msg Message) Message {
 		}
 	}
 
-	for {
-		switch p.tok.typ {
-		case messageNewline:
+	for _, fragment := range fragments {
+		switch fragment := fragment.(type) {
+		case *LineBreak:
 			if len(msg.Text) != 0 || len(msg.Replacements) != 0 {
 				appendText(" ")
 			}
-			p.next()
-
-		case messageText:
-			appendText(p.parseText())
 
-		case replacementStart:
-			repl := p.parseReplacement(len(msg.Text))
-			msg.Replacements = append(msg.Replacements, repl)
+		case *Text:
+			appendText(fragment.Value)
 
-		default:
-			return msg
+		case *ReplacementExpr:
+			repl := p.parseReplacement(len(msg.Text), fragment)
+			if markup, isMarkup := repl.Details.Value.(markupContent); isMarkup {
+				msg = appendMarkup(msg, repl, markup.content)
+			} else {
+				msg.Replacements = append(msg.Replacements, repl)
+			}
 		}
 	}
-
-}
-
-func (p *parser) parseText() string {
-	txt := p.tok.val
-	p.next()
-	return txt
+	return msg
 }
 
-func (p *parser) parseReplacement(textPos int) (repl Replacement) {
+func (p *parser) parseReplacement(textPos int, expr *ReplacementExpr) (repl Replacement) {
 	repl.TextPos = textPos
-	repl.Key = p.tok.val
-	p.next()
+	repl.Key = expr.Key
+
+	switch {
+	case repl.Key == "#":
+		return p.parsePluralCount(repl, expr)
+	case strings.HasPrefix(repl.Key, "@"):
+		return p.parseReference(repl, expr)
+	}
 
 	typ := "string"
-	if p.tok.typ == replacementType {
-		typ = p.tok.val
-		p.next()
+	if expr.Type != "" {
+		typ = expr.Type
 	}
 
 	switch strings.ToLower(typ) {
 	case "string":
 		repl.Type = StringReplacement
-		repl.Details = p.parseStringDetails()
+		repl.Details = p.parseStringDetails(expr)
 	case "number":
 		repl.Type = NumberReplacement
-		repl.Details = p.parseNumberDetails()
+		repl.Details = p.parseNumberDetails(expr)
 	case "percent":
 		repl.Type = PercentReplacement
-		repl.Details = p.parsePercentDetails()
+		repl.Details = p.parsePercentDetails(expr)
 	case "money":
 		repl.Type = MoneyReplacement
-		repl.Details = p.parseMoneyDetails()
+		repl.Details = p.parseMoneyDetails(expr)
 	case "plural":
 		repl.Type = PluralReplacement
-		repl.Details = p.parsePluralDetails()
+		p.plurals = append(p.plurals, repl.Key)
+		repl.Details = p.parsePluralDetails(expr)
+		p.plurals = p.plurals[:len(p.plurals)-1]
 	case "select":
 		repl.Type = SelectReplacement
-		repl.Details = p.parseSelectDetails()
+		repl.Details = p.parseSelectDetails(expr)
+	case "pluralrange":
+		repl.Type = PluralRangeReplacement
+		repl.Details = p.parsePluralRangeDetails(expr)
+	case "tag":
+		repl.Type = MarkupReplacement
+		repl.Details = p.parseMarkupDetails(expr)
 	default:
-		p.errorf("invalid replacement type: %s", typ)
-		p.skipReplacementOptions()
+		p.suggestf(expr, suggest("", typ, replacementTypeNames...), "invalid replacement type: %s", typ)
+		p.skipReplacementOptions(expr)
 	}
+	return repl
+}
+
+func (p *parser) parsePluralCount(repl Replacement, expr *ReplacementExpr) Replacement {
+	switch {
+	case len(p.plurals) == 0:
+		p.errorf(expr, "placeholder ${#} only allowed in plural options")
+	case expr.Type != "":
+		p.errorf(expr, "type not allowed for placeholder ${#}")
+	}
+	p.skipReplacementOptions(expr)
 
-	p.expect(replacementEnd)
+	if len(p.plurals) != 0 {
+		repl.Key = p.plurals[len(p.plurals)-1]
+	}
+	repl.Type = PluralCountReplacement
+	repl.Details = ReplacementDetails{Value: EmptyDetails{}}
 	return repl
 }
 
-func (p *parser) parseStringDetails() ReplacementDetails {
-	p.skipReplacementOptions()
+// parseReference parses a message reference. A reference of the form ${@key}
+// refers to a message in the current section, ${@section.key} to a message in
+// the given section, and ${@.key} to a message without any section.
+func (p *parser) parseReference(repl Replacement, expr *ReplacementExpr) Replacement {
+	ref := referenceDetails{section: p.section, pos: expr.Start(), end: expr.End()}
+	path := repl.Key[1:] // trim '@'
+	if idx := strings.LastIndexByte(path, '.'); idx >= 0 {
+		ref.section, ref.key = path[:idx], path[idx+1:]
+	} else {
+		ref.key = path
+	}
+
+	switch {
+	case ref.key == "":
+		p.errorf(expr, "invalid message reference: %s", repl.Key)
+	case expr.Type != "":
+		p.errorf(expr, "type not allowed for message reference %s", repl.Key)
+	}
+	p.skipReplacementOptions(expr)
+
+	repl.Details = ReplacementDetails{Value: ref}
+	return repl
+}
+
+func (p *parser) parseStringDetails(expr *ReplacementExpr) ReplacementDetails {
+	p.skipReplacementOptions(expr)
 	return ReplacementDetails{Value: EmptyDetails{}}
 }
 
-func (p *parser) parseNumberDetails() ReplacementDetails {
-	p.skipReplacementOptions()
+func (p *parser) parseNumberDetails(expr *ReplacementExpr) ReplacementDetails {
+	p.skipReplacementOptions(expr)
 	return ReplacementDetails{Value: EmptyDetails{}}
 }
 
-func (p *parser) parsePercentDetails() ReplacementDetails {
-	p.skipReplacementOptions()
+func (p *parser) parsePercentDetails(expr *ReplacementExpr) ReplacementDetails {
+	p.skipReplacementOptions(expr)
 	return ReplacementDetails{Value: EmptyDetails{}}
 }
 
-func (p *parser) parseMoneyDetails() ReplacementDetails {
+func (p *parser) parseMoneyDetails(expr *ReplacementExpr) ReplacementDetails {
 	details := MoneyDetails{}
 
 	hasCurrency := false
-	for p.tok.typ == replacementOptionStart {
-		option := p.tok.val
-		p.next()
-
-		msg := p.parseMessageFragments(Message{})
+	for _, opt := range expr.Options {
+		option := opt.Name
+		msg := p.parseMessageFragments(Message{}, opt.Value)
 		switch strings.ToLower(option) {
 		case "currency":
 			switch {
 			case hasCurrency:
-				p.errorf("money option already defined: .currency")
+				p.errorf(opt, "money option already defined: .currency")
 			case len(msg.Replacements) != 0:
-				p.errorf("replacements not allowed for money option .currency")
+				p.errorf(opt, "replacements not allowed for money option .currency")
 			case len(msg.Text) == 0:
-				p.errorf("empty money option .currency")
+				p.errorf(opt, "empty money option .currency")
 			default:
 				details.Currency = msg.Text[0]
 			}
 			hasCurrency = true
 
 		default:
-			p.errorf("invalid money option: .%s", option)
+			p.suggestf(opt, suggest(".", option, "currency"), "invalid money option: .%s", option)
 		}
-
-		p.expect(replacementOptionEnd)
 	}
 
 	if !hasCurrency {
-		p.errorf("money option .currency required")
+		p.errorf(expr, "money option .currency required")
 	}
 	return ReplacementDetails{Value: details}
 }
 
-func (p *parser) parsePluralDetails() ReplacementDetails {
+func (p *parser) parsePluralDetails(expr *ReplacementExpr) ReplacementDetails {
 	details := PluralDetails{
-		Type:     Cardinal,
-		Variants: make(map[PluralCategory]Message),
-		Custom:   make(map[int64]Message),
+		Type:           Cardinal,
+		Variants:       make(map[PluralCategory]Message),
+		Custom:         make(map[int64]Message),
+		CustomDecimals: make(map[string]Message),
 	}
 
 	typ := ""
-	for p.tok.typ == replacementOptionStart {
-		option := p.tok.val
-		p.next()
+	hasOffset := false
+	for _, opt := range expr.Options {
+		option := opt.Name
 
-		if option[0] == '[' {
+		if strings.ToLower(option) == "offset" {
+			msg := p.parseMessageFragments(Message{}, opt.Value)
+			switch {
+			case hasOffset:
+				p.errorf(opt, "plural option already defined: .offset")
+			case len(msg.Replacements) != 0:
+				p.errorf(opt, "replacements not allowed for plural option .offset")
+			case len(msg.Text) == 0:
+				p.errorf(opt, "empty plural option .offset")
+			default:
+				n, err := strconv.ParseInt(msg.Text[0], 10, 64)
+				if err != nil || n < 0 {
+					p.errorf(opt, "invalid plural offset: %s", msg.Text[0])
+				} else {
+					details.Offset = n
+				}
+			}
+			hasOffset = true
+		} else if strings.HasPrefix(option, "[") {
 			option = option[1 : len(option)-1] // trim '[' and ']'
-			n, err := strconv.ParseInt(option, 10, 64)
-			if err != nil {
-				p.errorf("invalid plural option: .[%s]", option)
-			} else if _, has := details.Custom[n]; has {
-				p.errorf("plural option already defined: .[%s]", option)
+			if n, err := strconv.ParseInt(option, 10, 64); err == nil {
+				if _, has := details.Custom[n]; has {
+					p.errorf(opt, "plural option already defined: .[%s]", option)
+				}
+				details.Custom[n] = p.parseMessageFragments(Message{Key: option}, opt.Value)
+			} else if dec, ok := normalizeDecimal(option); ok {
+				if _, has := details.CustomDecimals[dec]; has {
+					p.errorf(opt, "plural option already defined: .[%s]", option)
+				}
+				details.CustomDecimals[dec] = p.parseMessageFragments(Message{Key: dec}, opt.Value)
+			} else {
+				p.errorf(opt, "invalid plural option: .[%s]", option)
+				p.parseMessageFragments(Message{}, opt.Value)
 			}
-			details.Custom[n] = p.parseMessageFragments(Message{Key: option})
 		} else {
 			option = strings.ToLower(option)
 			tag := PluralCategory(-1)
 			switch option {
 			case "cardinal", "ordinal":
 				if typ != "" && typ != option {
-					p.errorf("multiple plural types defined (%s and %s)", typ, option)
+					p.errorf(opt, "multiple plural types defined (%s and %s)", typ, option)
 				} else if option == "ordinal" {
 					details.Type = Ordinal
 				} else {
 					details.Type = Cardinal
 				}
 				typ = option
-			case "zero":
-				tag = Zero
-			case "one":
-				tag = One
-			case "two":
-				tag = Two
-			case "few":
-				tag = Few
-			case "many":
-				tag = Many
-			case "other":
-				tag = Other
 			default:
-				p.errorf("invalid plural option: .%s", option)
+				var ok bool
+				if tag, ok = pluralCategoryOf(option); !ok {
+					candidates := append([]string{"cardinal", "ordinal", "offset"}, pluralCategoryNames[:]...)
+					p.suggestf(opt, suggest(".", option, candidates...), "invalid plural option: .%s", option)
+				}
 			}
 
 			if tag < 0 {
-				p.parseMessageFragments(Message{})
+				p.parseMessageFragments(Message{}, opt.Value)
 			} else {
 				if details.Variants[tag].Key != "" {
-					p.errorf("plural option already defined: .%s", option)
+					p.errorf(opt, "plural option already defined: .%s", option)
 				}
-				details.Variants[tag] = p.parseMessageFragments(Message{Key: option})
+				details.Variants[tag] = p.parseMessageFragments(Message{Key: option}, opt.Value)
 			}
 		}
-
-		p.expect(replacementOptionEnd)
 	}
 
 	if details.Variants[Other].Key == "" {
-		p.errorf("plural option .other required")
+		p.errorf(expr, "plural option .other required")
+	}
+	return ReplacementDetails{Value: details}
+}
+
+func (p *parser) parsePluralRangeDetails(expr *ReplacementExpr) ReplacementDetails {
+	details := PluralRangeDetails{
+		Variants: make(map[PluralCategory]Message),
+	}
+
+	hasEnd := false
+	for _, opt := range expr.Options {
+		option := strings.ToLower(opt.Name)
+
+		if option == "to" {
+			msg := p.parseMessageFragments(Message{}, opt.Value)
+			switch {
+			case hasEnd:
+				p.errorf(opt, "plural range option already defined: .to")
+			case len(msg.Replacements) != 0:
+				p.errorf(opt, "replacements not allowed for plural range option .to")
+			case len(msg.Text) == 0:
+				p.errorf(opt, "empty plural range option .to")
+			default:
+				details.End = msg.Text[0]
+			}
+			hasEnd = true
+		} else if tag, ok := pluralCategoryOf(option); ok {
+			if details.Variants[tag].Key != "" {
+				p.errorf(opt, "plural range option already defined: .%s", option)
+			}
+			details.Variants[tag] = p.parseMessageFragments(Message{Key: option}, opt.Value)
+		} else {
+			candidates := append([]string{"to"}, pluralCategoryNames[:]...)
+			p.suggestf(opt, suggest(".", option, candidates...), "invalid plural range option: .%s", option)
+			p.parseMessageFragments(Message{}, opt.Value)
+		}
+	}
+
+	switch {
+	case !hasEnd:
+		p.errorf(expr, "plural range option .to required")
+	case details.Variants[Other].Key == "":
+		p.errorf(expr, "plural range option .other required")
 	}
 	return ReplacementDetails{Value: details}
 }
 
-func (p *parser) parseSelectDetails() ReplacementDetails {
+// markupContent holds the content of a markup tag. It only exists during parsing,
+// where the tag is lowered into an opening tag, the content, and a closing tag.
+type markupContent struct {
+	content Message
+}
+
+// parseMarkupDetails parses a markup tag. A tag either has a content, e.g.
+// ${link:tag{terms}}, or it is a self-closing tag like ${br:tag}.
+func (p *parser) parseMarkupDetails(expr *ReplacementExpr) ReplacementDetails {
+	var content *Message
+	for _, opt := range expr.Options {
+		option := opt.Name
+
+		msg := p.parseMessageFragments(Message{}, opt.Value)
+		if option != "" {
+			p.errorf(opt, "invalid tag option: .%s", option)
+		} else {
+			content = &msg
+		}
+	}
+
+	if content == nil {
+		return ReplacementDetails{Value: MarkupDetails{Kind: SelfClosingTag}}
+	}
+	return ReplacementDetails{Value: markupContent{content: *content}}
+}
+
+// appendMarkup appends the opening tag, the content, and the closing tag of a
+// markup replacement to the message.
+func appendMarkup(msg Message, repl Replacement, content Message) Message {
+	b := messageBuilder{text: msg.Text, replacements: msg.Replacements}
+
+	repl.Details = ReplacementDetails{Value: MarkupDetails{Kind: OpenTag}}
+	b.appendReplacement(repl)
+	b.appendMessage(content)
+	repl.Details = ReplacementDetails{Value: MarkupDetails{Kind: CloseTag}}
+	b.appendReplacement(repl)
+
+	msg.Text, msg.Replacements = b.text, b.replacements
+	return msg
+}
+
+func (p *parser) parseSelectDetails(expr *ReplacementExpr) ReplacementDetails {
 	details := SelectDetails{
 		Cases: make(map[string]Message),
 	}
 
 	opts := make(map[string]struct{})
-	for p.tok.typ == replacementOptionStart {
-		option := p.tok.val
-		p.next()
+	for _, opt := range expr.Options {
+		option := opt.Name
 
-		if option[0] == '[' {
+		if strings.HasPrefix(option, "[") {
 			option = option[1 : len(option)-1] // trim '[' and ']'
 			if _, has := details.Cases[option]; has {
-				p.errorf("select option already defined: .[%s]", option)
+				p.errorf(opt, "select option already defined: .[%s]", option)
 			}
-			details.Cases[option] = p.parseMessageFragments(Message{Key: option})
+			details.Cases[option] = p.parseMessageFragments(Message{Key: option}, opt.Value)
 		} else {
 			if _, has := opts[option]; has {
-				p.errorf("select option already defined: .%s", option)
+				p.errorf(opt, "select option already defined: .%s", option)
 			}
 			opts[option] = struct{}{}
 
 			optval := ""
-			msg := p.parseMessageFragments(Message{})
+			msg := p.parseMessageFragments(Message{}, opt.Value)
 			switch {
 			case len(msg.Replacements) != 0:
-				p.errorf("replacements not allowed in select option .%s", option)
+				p.errorf(opt, "replacements not allowed in select option .%s", option)
 			case len(msg.Text) != 0:
 				optval = msg.Text[0]
 			}
@@ -294,43 +569,92 @@ func (p *parser) parseSelectDetails() ReplacementDetails {
 				details.Fallback = optval
 			}
 		}
-
-		p.expect(replacementOptionEnd)
 	}
 
 	if _, hasFallback := details.Cases[details.Fallback]; details.Fallback != "" && !hasFallback {
-		p.errorf("default value %q not found in select options", details.Fallback)
+		p.errorf(expr, "default value %q not found in select options", details.Fallback)
 	}
 	return ReplacementDetails{Value: details}
 }
 
-func (p *parser) skipReplacementOptions() {
-	for p.tok.typ == replacementOptionStart {
-		p.next() // skip option name
-		p.parseMessageFragments(Message{})
-		p.expect(replacementOptionEnd)
+// normalizeDecimal checks whether s is a decimal number with visible fraction
+// digits (e.g. "-1.50") and strips the leading zeros of the integer digits. The
+// fraction digits are kept as they are, since they are relevant for the plural
+// operands.
+func normalizeDecimal(s string) (string, bool) {
+	sign := ""
+	if len(s) != 0 && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = "-"
+		}
+		s = s[1:]
+	}
+
+	intDigits, fracDigits, hasFrac := strings.Cut(s, ".")
+	if !hasFrac || intDigits == "" || fracDigits == "" || !isDigits(intDigits) || !isDigits(fracDigits) {
+		return "", false
+	}
+
+	intDigits = strings.TrimLeft(intDigits, "0")
+	if intDigits == "" {
+		intDigits = "0"
 	}
+	return sign + intDigits + "." + fracDigits, true
 }
 
-func (p *parser) expect(typ tokenType) {
-	if p.tok.typ != typ {
-		p.errorf("unexpected token %q", p.tok.val)
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
 	}
-	p.next()
+	return true
 }
 
-func (p *parser) errorf(format string, args ...interface{}) {
-	p.errs.add(errors.Newf(format, args...), p.tok.pos)
+func pluralCategoryOf(option string) (PluralCategory, bool) {
+	switch option {
+	case "zero":
+		return Zero, true
+	case "one":
+		return One, true
+	case "two":
+		return Two, true
+	case "few":
+		return Few, true
+	case "many":
+		return Many, true
+	case "other":
+		return Other, true
+	default:
+		return -1, false
+	}
 }
 
-func (p *parser) next() {
-	var ok bool
-	p.tok, ok = <-p.t.tokens
+func (p *parser) skipReplacementOptions(expr *ReplacementExpr) {
+	for _, opt := range expr.Options {
+		p.parseMessageFragments(Message{}, opt.Value)
+	}
+}
 
-	switch {
-	case !ok:
-		p.tok.typ = eof
-	case p.tok.typ == invalid:
-		p.errorf(p.tok.val)
+func (p *parser) errorf(n Node, format string, args ...interface{}) {
+	p.suggestf(n, "", format, args...)
+}
+
+// suggestf reports an error for the node together with a hint how to fix it.
+func (p *parser) suggestf(n Node, suggestion string, format string, args ...interface{}) {
+	p.report(Error{
+		Err:        errors.Newf(format, args...),
+		Pos:        n.Start(),
+		End:        n.End(),
+		Suggestion: suggestion,
+	})
+}
+
+// report adds an error together with the chain of include directives which led
+// to the current file.
+func (p *parser) report(err Error) {
+	for i := len(p.includes) - 1; i >= 0; i-- {
+		err.Includes = append(err.Includes, p.includes[i])
 	}
+	p.errs = append(p.errs, err)
 }
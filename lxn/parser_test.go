@@ -1,6 +1,8 @@
 package lxn
 
 import (
+	"fmt"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -43,6 +45,9 @@ key-seven:
 		.One{bar}
 		.OTHER{foobar}
 		.[7]{seven}
+		.[-1]{minus one}
+		.[0.5]{half}
+		.[01.0]{one point zero}
 	}
 
 key-eight:
@@ -63,6 +68,20 @@ key-nine:
 	}
 	created this message
 
+key-eleven:
+	${from:pluralrange
+	.to{to}
+	.one{${from}-${to} day}
+	.other{${from}-${to} days}
+	}
+
+key-twelve:
+	${count:plural
+	.offset{1}
+	.one{you and ${#} other}
+	.other{you and ${#} others}
+	}
+
 key-ten:
 	line 1
 	${param1}
@@ -167,7 +186,12 @@ key-ten:
 								Other: {Key: "other", Text: []string{"foobar"}},
 							},
 							Custom: map[int64]Message{
-								7: {Key: "7", Text: []string{"seven"}},
+								7:  {Key: "7", Text: []string{"seven"}},
+								-1: {Key: "-1", Text: []string{"minus one"}},
+							},
+							CustomDecimals: map[string]Message{
+								"0.5": {Key: "0.5", Text: []string{"half"}},
+								"1.0": {Key: "1.0", Text: []string{"one point zero"}},
 							},
 						},
 					},
@@ -194,6 +218,7 @@ key-ten:
 							Custom: map[int64]Message{
 								7: {Key: "7", Text: []string{"seven"}},
 							},
+							CustomDecimals: map[string]Message{},
 						},
 					},
 				},
@@ -222,6 +247,70 @@ key-ten:
 				},
 			},
 		},
+		{
+			Section: "section.two",
+			Key:     "key-eleven",
+			Replacements: []Replacement{
+				{
+					Key:     "from",
+					TextPos: 0,
+					Type:    PluralRangeReplacement,
+					Details: ReplacementDetails{
+						Value: PluralRangeDetails{
+							End: "to",
+							Variants: map[PluralCategory]Message{
+								One: {
+									Key:  "one",
+									Text: []string{"-", " day"},
+									Replacements: []Replacement{
+										{Key: "from", TextPos: 0, Type: StringReplacement, Details: emptyReplacementDetails},
+										{Key: "to", TextPos: 1, Type: StringReplacement, Details: emptyReplacementDetails},
+									},
+								},
+								Other: {
+									Key:  "other",
+									Text: []string{"-", " days"},
+									Replacements: []Replacement{
+										{Key: "from", TextPos: 0, Type: StringReplacement, Details: emptyReplacementDetails},
+										{Key: "to", TextPos: 1, Type: StringReplacement, Details: emptyReplacementDetails},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Section: "section.two",
+			Key:     "key-twelve",
+			Replacements: []Replacement{
+				{
+					Key:     "count",
+					TextPos: 0,
+					Type:    PluralReplacement,
+					Details: ReplacementDetails{
+						Value: PluralDetails{
+							Variants: map[PluralCategory]Message{
+								One: {
+									Key:          "one",
+									Text:         []string{"you and ", " other"},
+									Replacements: []Replacement{{Key: "count", TextPos: 1, Type: PluralCountReplacement, Details: emptyReplacementDetails}},
+								},
+								Other: {
+									Key:          "other",
+									Text:         []string{"you and ", " others"},
+									Replacements: []Replacement{{Key: "count", TextPos: 1, Type: PluralCountReplacement, Details: emptyReplacementDetails}},
+								},
+							},
+							Custom:         map[int64]Message{},
+							Offset:         1,
+							CustomDecimals: map[string]Message{},
+						},
+					},
+				},
+			},
+		},
 		{
 			Section: "section.two",
 			Key:     "key-ten",
@@ -272,6 +361,18 @@ key-one:
 	${foo:select.default{${bar:string}}}
 	${foo:select.default{baz ${bar:string}}}
 	${foo:select.default{a}.[b]{some text}}
+	${foo:pluralrange.other{}}
+	${foo:pluralrange.to{bar}.one{}}
+	${foo:pluralrange.to{bar}.to{baz}.other{}.all{}}
+	${foo:plural.[1.5]{}.[01.5]{}.[1.]{}.other{}}
+	${foo:plural.offset{-1}.other{}}
+	${foo:plural.offset{1}.offset{2}.other{}}
+	${#}
+	${foo:plural.other{${#:number}}}
+	${@section.}
+	${@section.key:string}
+	${foo:select.}
+	${foo:plural.other{}.}
 	`
 
 	expectedErrors := [...]string{
@@ -293,6 +394,19 @@ key-one:
 		"replacements not allowed in select option .default",
 		"replacements not allowed in select option .default",
 		"default value \"a\" not found in select options",
+		"plural range option .to required",
+		"plural range option .other required",
+		"plural range option already defined: .to",
+		"invalid plural range option: .all",
+		"plural option already defined: .[01.5]",
+		"invalid plural option: .[1.]",
+		"invalid plural offset: -1",
+		"plural option already defined: .offset",
+		"placeholder ${#} only allowed in plural options",
+		"type not allowed for placeholder ${#}",
+		"invalid message reference: @section.",
+		"type not allowed for message reference @section.key",
+		"invalid plural option: .",
 	}
 
 	var p parser
@@ -318,3 +432,310 @@ key-one:
 		}
 	}
 }
+
+func TestParserWithInvalidOffset(t *testing.T) {
+	for _, offset := range []string{"-1", "x", "99999999999999999999"} {
+		var p parser
+		msgs, err := p.Parse("test", []byte("key: ${foo:plural.offset{"+offset+"}.other{}}"))
+		if err == nil {
+			t.Errorf("expected error for offset %s", offset)
+			continue
+		}
+
+		if len(msgs) != 1 || len(msgs[0].Replacements) != 1 {
+			t.Fatalf("unexpected messages for offset %s: %+v", offset, msgs)
+		}
+		details := msgs[0].Replacements[0].Details.Value.(PluralDetails)
+		if details.Offset != 0 {
+			t.Errorf("unexpected offset for %s: %d", offset, details.Offset)
+		}
+	}
+}
+
+func TestParserRecovery(t *testing.T) {
+	const input = "first: ${foo bar} text\n" +
+		"second: a } b\n" +
+		"} stray\n" +
+		"third: ${foo:unknowntype}\n" +
+		"[[sec]]\n" +
+		"  indented\n" +
+		"fourth: ${foo:select .[a]{x}junk .default{a}}\n" +
+		"missing colon\n" +
+		"fifth: ${foo\n" +
+		"sixth: ok\n"
+
+	// The syntax errors are reported first, the errors of the messages which
+	// are parsed after recovering follow.
+	expectedErrors := []string{
+		"test:1:13: unexpected token 'b' ('}' expected)",
+		"test:2:10: unexpected token '}' (no replacement to close)",
+		"test:3:0: unexpected token '}' (message key expected)",
+		"test:6:2: unexpected indentation",
+		"test:7:28: unexpected token 'j' ('}' expected)",
+		"test:8:7: unexpected token ' ' (':' expected)",
+		"test:9:7: unclosed replacement ('}' expected)",
+		"test:4:7: invalid replacement type: unknowntype",
+	}
+	expectedKeys := []string{"first", "second", "third", "sec.fourth", "sec.missing", "sec.fifth", "sec.sixth"}
+
+	var p parser
+	msgs, err := p.Parse("test", []byte(input))
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(errs) != len(expectedErrors) {
+		t.Errorf("unexpected number of errors: %d (%q)", len(errs), []error(errs))
+	} else {
+		for i, err := range errs {
+			if msg := err.Error(); msg != expectedErrors[i] {
+				t.Errorf("unexpected error message: %s", msg)
+			}
+		}
+	}
+
+	var keys []string
+	for _, msg := range msgs {
+		key := msg.Key
+		if msg.Section != "" {
+			key = msg.Section + "." + key
+		}
+		keys = append(keys, key)
+	}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("unexpected messages: %v", keys)
+	}
+}
+
+func TestParserWithInvalidCharacters(t *testing.T) {
+	const input = "key: a\x00b\xffc\n"
+
+	var p parser
+	msgs, err := p.Parse("test", []byte(input))
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedErrors := []string{"test:1:6: invalid character nul", "test:1:8: invalid encoding"}
+	if len(errs) != len(expectedErrors) {
+		t.Errorf("unexpected number of errors: %d (%q)", len(errs), []error(errs))
+	} else {
+		for i, err := range errs {
+			if msg := err.Error(); msg != expectedErrors[i] {
+				t.Errorf("unexpected error message: %s", msg)
+			}
+		}
+	}
+
+	if len(msgs) != 1 {
+		t.Fatalf("unexpected number of messages: %d", len(msgs))
+	}
+	if expected := []string{"abc"}; !reflect.DeepEqual(msgs[0].Text, expected) {
+		t.Errorf("unexpected message text: %q", msgs[0].Text)
+	}
+}
+
+func TestParserWithIncludes(t *testing.T) {
+	files := map[string]string{
+		"main.lxn":         "[[main]]\nkey-one: one\n@include \"common/units.lxn\"\nkey-two: two\n",
+		"common/units.lxn": "meter: m\n[[units]]\n@include \"../more.lxn\"\nsecond: s\n",
+		"more.lxn":         "gram: g\n",
+	}
+	readFile := func(filename string) ([]byte, error) {
+		if content, has := files[filepath.ToSlash(filename)]; has {
+			return []byte(content), nil
+		}
+		return nil, fmt.Errorf("file not found: %s", filename)
+	}
+
+	p := parser{readFile: readFile}
+	msgs, err := p.Parse("main.lxn", []byte(files["main.lxn"]))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"main.key-one", "meter", "gram", "units.second", "main.key-two"}
+	if len(msgs) != len(expected) {
+		t.Fatalf("unexpected number of messages: %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		key := msg.Key
+		if msg.Section != "" {
+			key = msg.Section + "." + key
+		}
+		if key != expected[i] {
+			t.Errorf("unexpected message key at %d: %s", i, key)
+		}
+	}
+}
+
+func TestParserWithIncludeErrors(t *testing.T) {
+	files := map[string]string{
+		"a.lxn": "@include \"b.lxn\"\n",
+		"b.lxn": "key: ${foo:unknowntype}\n@include \"a.lxn\"\n@include \"c.lxn\"\n",
+	}
+	readFile := func(filename string) ([]byte, error) {
+		if content, has := files[filepath.ToSlash(filename)]; has {
+			return []byte(content), nil
+		}
+		return nil, fmt.Errorf("file not found: %s", filename)
+	}
+
+	p := parser{readFile: readFile}
+	_, err := p.Parse("a.lxn", []byte(files["a.lxn"]))
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+
+	expected := []string{
+		"b.lxn:1:5: invalid replacement type: unknowntype (included from a.lxn:1:0)",
+		"b.lxn:2:0: include cycle: a.lxn -> b.lxn -> a.lxn (included from a.lxn:1:0)",
+		"b.lxn:3:0: file not found: c.lxn (included from a.lxn:1:0)",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected number of errors: %d (%v)", len(errs), errs)
+	}
+	for i, err := range errs {
+		if msg := err.Error(); msg != expected[i] {
+			t.Errorf("unexpected error message: %s", msg)
+		}
+	}
+
+	p = parser{}
+	_, err = p.Parse("a.lxn", []byte(files["a.lxn"]))
+	if err == nil || err.(ErrorList)[0].(Error).Err.Error() != "include directives not supported" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParserWithNotes(t *testing.T) {
+	const input = `
+/// Greets the user.
+/// @desc Shown after login.
+/// @context header
+/// @maxlen 20
+greeting: Hello
+/// Discarded by the section header.
+[[section]]
+// regular comment
+farewell: Bye
+`
+
+	var p parser
+	msgs, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Notes{
+		{Description: "Greets the user. Shown after login.", Context: "header", MaxLength: 20},
+		{},
+	}
+	if len(msgs) != len(expected) {
+		t.Fatalf("unexpected number of messages: %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.Notes != expected[i] {
+			t.Errorf("unexpected notes for %s: %+v", msg.Key, msg.Notes)
+		}
+	}
+
+	_, err = p.Parse("test", []byte("/// @maxlen ten\n/// @foo bar\nkey: text\n"))
+	errs, ok := err.(ErrorList)
+	switch {
+	case !ok:
+		t.Errorf("unexpected error type: %T", err)
+	case len(errs) != 2:
+		t.Errorf("unexpected number of errors: %d", len(errs))
+	case errs[0].(Error).Err.Error() != `invalid @maxlen value: "ten"`:
+		t.Errorf("unexpected error message: %v", errs[0])
+	case errs[1].(Error).Err.Error() != "unknown annotation: @foo":
+		t.Errorf("unexpected error message: %v", errs[1])
+	}
+}
+
+func TestParserWithVerbatimBlocks(t *testing.T) {
+	const input = "help: |\n" +
+		"\tUsage:\n" +
+		"\t  lxnc ${command} <file>   \n" +
+		"\n" +
+		"\t+-----+\n" +
+		"\t| box |\n" +
+		"\n" +
+		"next: folded\n" +
+		"\ttext\n"
+
+	var p parser
+	msgs, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Message{
+		{
+			Key:  "help",
+			Text: []string{"Usage:\n  lxnc ", " <file>   \n\n+-----+\n| box |"},
+			Replacements: []Replacement{
+				{Key: "command", TextPos: 1, Type: StringReplacement, Details: ReplacementDetails{Value: EmptyDetails{}}},
+			},
+		},
+		{
+			Key:  "next",
+			Text: []string{"folded text"},
+		},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("unexpected messages: %#v", msgs)
+	}
+}
+
+func TestParserWithMarkup(t *testing.T) {
+	const input = "key: Read our ${link:tag{terms of ${name}}} and ${b:tag{privacy policy}}.${br:tag}\n"
+
+	var p parser
+	msgs, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	markup := func(key string, textPos int, kind MarkupKind) Replacement {
+		return Replacement{Key: key, TextPos: textPos, Type: MarkupReplacement, Details: ReplacementDetails{Value: MarkupDetails{Kind: kind}}}
+	}
+	expected := []Message{
+		{
+			Key:  "key",
+			Text: []string{"Read our ", "terms of ", " and ", "privacy policy", "."},
+			Replacements: []Replacement{
+				markup("link", 1, OpenTag),
+				{Key: "name", TextPos: 2, Type: StringReplacement, Details: ReplacementDetails{Value: EmptyDetails{}}},
+				markup("link", 2, CloseTag),
+				markup("b", 3, OpenTag),
+				markup("b", 4, CloseTag),
+				markup("br", 5, SelfClosingTag),
+			},
+		},
+	}
+	if !reflect.DeepEqual(msgs, expected) {
+		t.Errorf("unexpected messages: %#v", msgs)
+	}
+
+	_, err = p.Parse("test", []byte("key: ${link:tag .href{foo}}\n"))
+	if errs, ok := err.(ErrorList); !ok || len(errs) != 1 || errs[0].(Error).Err.Error() != "invalid tag option: .href" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	input := benchmarkInput("section", 1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var p parser
+		if _, err := p.Parse("bench.lxn", input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
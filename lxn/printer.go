@@ -0,0 +1,103 @@
+package lxn
+
+import (
+	"io"
+	"strings"
+)
+
+// PrintFile writes the syntax tree of an lxn file as source text. The output is
+// in canonical form: message bodies are indented with tabs and multiple empty
+// lines between declarations are collapsed into a single one. Parsing the output
+// yields the same messages as the original source.
+func PrintFile(w io.Writer, file *File) error {
+	var p printer
+	p.printFile(file)
+	_, err := io.WriteString(w, p.buf.String())
+	return err
+}
+
+type printer struct {
+	buf strings.Builder
+}
+
+func (p *printer) printFile(file *File) {
+	for i, decl := range file.Decls {
+		if i > 0 && decl.Start().Line > file.Decls[i-1].End().Line+1 {
+			p.buf.WriteByte('\n')
+		}
+
+		switch decl := decl.(type) {
+		case *Comment:
+			if decl.Doc {
+				p.buf.WriteString("///")
+			} else {
+				p.buf.WriteString("//")
+			}
+			p.buf.WriteString(decl.Text)
+		case *SectionDecl:
+			p.buf.WriteString("[[" + decl.Name + "]]")
+		case *IncludeDecl:
+			p.buf.WriteString("@include \"" + decl.Path + "\"")
+		case *MessageDecl:
+			p.printMessageDecl(decl)
+		}
+		p.buf.WriteByte('\n')
+	}
+}
+
+func (p *printer) printMessageDecl(decl *MessageDecl) {
+	p.buf.WriteString(decl.Key + ":")
+	switch {
+	case decl.Verbatim:
+		// The body of a verbatim block is printed separately, since each of its
+		// lines needs to be indented.
+		var body printer
+		body.printFragments(decl.Body, 0)
+		p.buf.WriteString(" |")
+		for _, line := range strings.Split(body.buf.String(), "\n") {
+			p.buf.WriteByte('\n')
+			if line != "" {
+				p.buf.WriteString("\t" + line)
+			}
+		}
+	case decl.Indented:
+		p.buf.WriteString("\n\t")
+		p.printFragments(decl.Body, 1)
+	case len(decl.Body) != 0:
+		p.buf.WriteByte(' ')
+		p.printFragments(decl.Body, 1)
+	}
+}
+
+// printFragments prints the fragments of a message body. Line breaks are
+// indented by the given depth.
+func (p *printer) printFragments(fragments []Fragment, depth int) {
+	for _, fragment := range fragments {
+		switch fragment := fragment.(type) {
+		case *Text:
+			p.buf.WriteString(fragment.Value)
+		case *LineBreak:
+			p.buf.WriteString("\n" + strings.Repeat("\t", depth))
+		case *ReplacementExpr:
+			p.printReplacementExpr(fragment, depth)
+		}
+	}
+}
+
+func (p *printer) printReplacementExpr(expr *ReplacementExpr, depth int) {
+	p.buf.WriteString("${" + expr.Key)
+	if expr.Type != "" {
+		p.buf.WriteString(":" + expr.Type)
+	}
+	for _, opt := range expr.Options {
+		if opt.Name != "" {
+			p.buf.WriteString(" ." + opt.Name)
+		}
+		if opt.HasValue {
+			p.buf.WriteByte('{')
+			p.printFragments(opt.Value, depth+1)
+			p.buf.WriteByte('}')
+		}
+	}
+	p.buf.WriteByte('}')
+}
@@ -0,0 +1,98 @@
+package lxn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestPrintFile(t *testing.T) {
+	const input = `/// Greets the user.
+greeting: Hello ${name}, you have ${count:plural .[0]{no items} .one{${#} item} .other{${#} items}}
+
+// regular comment
+[[cart]]
+@include "other.lxn"
+total:
+	Total: ${amount:money .currency{EUR}}
+	& ${link:tag{more}}${br:tag}
+multi: first line
+	second line ${n:plural .ordinal .one{${#}st
+		line} .other{${#}th}}
+verbatim: |
+	first line
+		indented line
+
+	after an empty line ${name}
+empty:
+`
+
+	file, err := ParseFile("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintFile(&buf, file); err != nil {
+		t.Fatalf("unexpected print error: %v", err)
+	}
+	if buf.String() != input {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestPrintFileCanonical(t *testing.T) {
+	const input = `
+
+
+key:    text
+  continued ${param:number
+       .foo{}}
+
+
+
+[[  section  ]]
+other:
+    ${gender:select .[male]{he} .[female]{she}}
+verbatim:   |
+    line one
+      line two
+`
+
+	const expected = `key: text
+	continued ${param:number .foo{}}
+
+[[section]]
+other:
+	${gender:select .[male]{he} .[female]{she}}
+verbatim: |
+	line one
+	  line two
+`
+
+	file, err := ParseFile("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintFile(&buf, file); err != nil {
+		t.Fatalf("unexpected print error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	var p parser
+	original, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	printed, err := p.Parse("test", buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if !reflect.DeepEqual(original, printed) {
+		t.Errorf("messages differ:\n%#v\n%#v", original, printed)
+	}
+}
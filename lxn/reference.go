@@ -0,0 +1,239 @@
+package lxn
+
+import (
+	"strings"
+
+	"github.com/liblxn/lxnc/internal/errors"
+)
+
+// referenceDetails holds the target of a message reference (${@section.key}).
+// References only exist between parsing and resolving and are never encoded.
+type referenceDetails struct {
+	section string
+	key     string
+	pos     Pos // start of the reference
+	end     Pos // end of the reference
+}
+
+type referenceTarget struct {
+	section string
+	key     string
+}
+
+func (t referenceTarget) String() string {
+	if t.section == "" {
+		return t.key
+	}
+	return t.section + "." + t.key
+}
+
+const (
+	unresolved = iota
+	resolving
+	resolved
+)
+
+// ResolveReferences replaces all message references (${@section.key}) with the
+// text and replacements of the referenced message. References are resolved
+// transitively. An error is returned for each reference whose target message does
+// not exist and for each reference cycle.
+func ResolveReferences(messages []Message) error {
+	r := referenceResolver{
+		messages: messages,
+		index:    make(map[referenceTarget]int, len(messages)),
+		state:    make([]int, len(messages)),
+	}
+	for i, msg := range messages {
+		target := referenceTarget{section: msg.Section, key: msg.Key}
+		if _, has := r.index[target]; !has {
+			r.index[target] = i
+		}
+	}
+
+	for i := range messages {
+		r.resolve(i)
+	}
+	return r.errs.err()
+}
+
+type referenceResolver struct {
+	messages []Message
+	index    map[referenceTarget]int
+	state    []int
+	stack    []referenceTarget
+	errs     ErrorList
+}
+
+func (r *referenceResolver) resolve(idx int) {
+	if r.state[idx] != unresolved {
+		return
+	}
+
+	msg := r.messages[idx]
+	r.state[idx] = resolving
+	r.stack = append(r.stack, referenceTarget{section: msg.Section, key: msg.Key})
+	r.messages[idx] = r.resolveMessage(msg)
+	r.stack = r.stack[:len(r.stack)-1]
+	r.state[idx] = resolved
+}
+
+func (r *referenceResolver) resolveMessage(msg Message) Message {
+	if !hasReferences(msg) {
+		return msg
+	}
+
+	var b messageBuilder
+	r.appendMessage(&b, msg)
+	msg.Text, msg.Replacements = b.text, b.replacements
+	return msg
+}
+
+func (r *referenceResolver) appendMessage(b *messageBuilder, msg Message) {
+	replIdx := 0
+	for i := 0; i <= len(msg.Text); i++ {
+		for ; replIdx < len(msg.Replacements) && msg.Replacements[replIdx].TextPos <= i; replIdx++ {
+			repl := msg.Replacements[replIdx]
+			if ref, isRef := repl.Details.Value.(referenceDetails); isRef {
+				if target, ok := r.lookup(ref); ok {
+					b.appendMessage(target)
+				}
+				continue
+			}
+
+			r.resolveDetails(repl.Details)
+			b.appendReplacement(repl)
+		}
+		if i < len(msg.Text) {
+			b.appendText(msg.Text[i])
+		}
+	}
+}
+
+func (r *referenceResolver) resolveDetails(details ReplacementDetails) {
+	resolveVariants := func(variants map[PluralCategory]Message) {
+		for cat, variant := range variants {
+			variants[cat] = r.resolveMessage(variant)
+		}
+	}
+
+	switch details := details.Value.(type) {
+	case PluralDetails:
+		resolveVariants(details.Variants)
+		for n, variant := range details.Custom {
+			details.Custom[n] = r.resolveMessage(variant)
+		}
+		for n, variant := range details.CustomDecimals {
+			details.CustomDecimals[n] = r.resolveMessage(variant)
+		}
+	case PluralRangeDetails:
+		resolveVariants(details.Variants)
+	case SelectDetails:
+		for c, variant := range details.Cases {
+			details.Cases[c] = r.resolveMessage(variant)
+		}
+	}
+}
+
+func (r *referenceResolver) lookup(ref referenceDetails) (Message, bool) {
+	target := referenceTarget{section: ref.section, key: ref.key}
+	idx, has := r.index[target]
+	if !has {
+		r.errs.addSpan(errors.Newf("unresolved message reference: @%s", target), ref.pos, ref.end)
+		return Message{}, false
+	}
+
+	if r.state[idx] == resolving {
+		cycle := make([]string, 0, len(r.stack)+1)
+		for i := len(r.stack) - 1; i >= 0; i-- {
+			if r.stack[i] == target {
+				for _, t := range r.stack[i:] {
+					cycle = append(cycle, t.String())
+				}
+				break
+			}
+		}
+		cycle = append(cycle, target.String())
+		r.errs.addSpan(errors.Newf("message reference cycle: %s", strings.Join(cycle, " -> ")), ref.pos, ref.end)
+		return Message{}, false
+	}
+
+	r.resolve(idx)
+	return r.messages[idx], true
+}
+
+func hasReferences(msg Message) bool {
+	hasAny := func(variants map[PluralCategory]Message) bool {
+		for _, variant := range variants {
+			if hasReferences(variant) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, repl := range msg.Replacements {
+		switch details := repl.Details.Value.(type) {
+		case referenceDetails:
+			return true
+		case PluralDetails:
+			if hasAny(details.Variants) {
+				return true
+			}
+			for _, variant := range details.Custom {
+				if hasReferences(variant) {
+					return true
+				}
+			}
+			for _, variant := range details.CustomDecimals {
+				if hasReferences(variant) {
+					return true
+				}
+			}
+		case PluralRangeDetails:
+			if hasAny(details.Variants) {
+				return true
+			}
+		case SelectDetails:
+			for _, variant := range details.Cases {
+				if hasReferences(variant) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// messageBuilder concatenates text fragments and replacements to a message
+// text. Adjacent text fragments are merged into a single one.
+type messageBuilder struct {
+	text         []string
+	replacements []Replacement
+}
+
+func (b *messageBuilder) appendText(text string) {
+	ntext := len(b.text)
+	nrepl := len(b.replacements)
+	if ntext != 0 && (nrepl == 0 || b.replacements[nrepl-1].TextPos < ntext) {
+		b.text[ntext-1] += text
+	} else {
+		b.text = append(b.text, text)
+	}
+}
+
+func (b *messageBuilder) appendReplacement(repl Replacement) {
+	repl.TextPos = len(b.text)
+	b.replacements = append(b.replacements, repl)
+}
+
+func (b *messageBuilder) appendMessage(msg Message) {
+	replIdx := 0
+	for i := 0; i <= len(msg.Text); i++ {
+		for ; replIdx < len(msg.Replacements) && msg.Replacements[replIdx].TextPos <= i; replIdx++ {
+			b.appendReplacement(msg.Replacements[replIdx])
+		}
+		if i < len(msg.Text) {
+			b.appendText(msg.Text[i])
+		}
+	}
+}
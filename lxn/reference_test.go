@@ -0,0 +1,99 @@
+package lxn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveReferences(t *testing.T) {
+	const input = `
+app-name: Example App
+welcome: Welcome to ${@app-name}, ${name}!
+
+[[cart]]
+items: ${count:plural .one{${@.app-name} item} .other{${#} items}}
+title: ${@items} in ${@.app-name}
+`
+
+	var p parser
+	messages, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if err := ResolveReferences(messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(messages) != 4 {
+		t.Fatalf("unexpected number of messages: %d", len(messages))
+	}
+
+	welcome := messages[1]
+	switch {
+	case !reflect.DeepEqual(welcome.Text, []string{"Welcome to Example App, ", "!"}):
+		t.Errorf("unexpected welcome text: %q", welcome.Text)
+	case len(welcome.Replacements) != 1:
+		t.Errorf("unexpected number of welcome replacements: %d", len(welcome.Replacements))
+	case welcome.Replacements[0].Key != "name" || welcome.Replacements[0].TextPos != 1:
+		t.Errorf("unexpected welcome replacement: %+v", welcome.Replacements[0])
+	}
+
+	items := messages[2].Replacements[0].Details.Value.(PluralDetails)
+	if one := items.Variants[One]; !reflect.DeepEqual(one.Text, []string{"Example App item"}) || len(one.Replacements) != 0 {
+		t.Errorf("unexpected plural variant: %+v", one)
+	}
+
+	title := messages[3]
+	switch {
+	case !reflect.DeepEqual(title.Text, []string{" in Example App"}):
+		t.Errorf("unexpected title text: %q", title.Text)
+	case len(title.Replacements) != 1:
+		t.Errorf("unexpected number of title replacements: %d", len(title.Replacements))
+	case title.Replacements[0].Key != "count" || title.Replacements[0].TextPos != 0 || title.Replacements[0].Type != PluralReplacement:
+		t.Errorf("unexpected title replacement: %+v", title.Replacements[0])
+	}
+}
+
+func TestResolveReferencesWithErrors(t *testing.T) {
+	const input = `
+a: ${@b}
+b: ${@c}
+c: ${@a}
+d: ${@unknown}
+[[section]]
+e: ${@.d} ${@other.key}
+`
+
+	var p parser
+	messages, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	err = ResolveReferences(messages)
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+
+	expected := []struct {
+		msg  string
+		line int
+	}{
+		{msg: "message reference cycle: a -> b -> c -> a", line: 4},
+		{msg: "unresolved message reference: @unknown", line: 5},
+		{msg: "unresolved message reference: @other.key", line: 7},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected number of errors: %d (%v)", len(errs), errs)
+	}
+	for i, e := range errs {
+		err := e.(Error)
+		switch {
+		case err.Err.Error() != expected[i].msg:
+			t.Errorf("unexpected error message: %s", err.Err)
+		case err.Pos.Line != expected[i].line:
+			t.Errorf("unexpected error line for %q: %d", err.Err, err.Pos.Line)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package lxn
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences for colored output.
+const (
+	colorReset  = "\x1b[0m"
+	colorError  = "\x1b[1;31m"
+	colorGutter = "\x1b[1;34m"
+	colorHelp   = "\x1b[1;36m"
+	colorBold   = "\x1b[1m"
+)
+
+// ErrorRenderer renders errors together with an excerpt of the source. The
+// offending text is underlined in the source line, for example:
+//
+//	error: invalid plural option: .othr
+//	 --> messages.lxn:1:30
+//	  |
+//	1 | key: ${count:plural .one{one} .othr{many}}
+//	  |                               ^^^^^^^^^^^
+//	  = help: did you mean .other?
+type ErrorRenderer struct {
+	Color bool // use ANSI colors, e.g. when writing to a terminal
+
+	// ReadFile reads the source files of the errors. If ReadFile is nil,
+	// os.ReadFile is used. Errors of files which cannot be read are rendered
+	// without an excerpt.
+	ReadFile func(filename string) ([]byte, error)
+
+	sources map[string][]byte
+}
+
+// Render writes the error to w. Error lists are rendered error by error, and
+// errors which do not originate from an lxn file are rendered as plain message.
+func (r *ErrorRenderer) Render(w io.Writer, err error) error {
+	var buf bytes.Buffer
+	r.render(&buf, err)
+	_, werr := w.Write(buf.Bytes())
+	return werr
+}
+
+func (r *ErrorRenderer) render(buf *bytes.Buffer, err error) {
+	switch err := err.(type) {
+	case ErrorList:
+		for _, e := range err {
+			r.render(buf, e)
+		}
+	case Error:
+		r.renderError(buf, err)
+	case *Error:
+		r.renderError(buf, *err)
+	default:
+		buf.WriteString(r.colored(colorError, "error") + r.colored(colorBold, ": "+err.Error()) + "\n")
+	}
+}
+
+func (r *ErrorRenderer) renderError(buf *bytes.Buffer, e Error) {
+	line := ""
+	gutter := " "
+	src, hasSource := r.source(e.Pos.File)
+	if hasSource {
+		line = strconv.Itoa(e.Pos.Line)
+		gutter = strings.Repeat(" ", len(line))
+	}
+
+	buf.WriteString(r.colored(colorError, "error") + r.colored(colorBold, ": "+e.Err.Error()) + "\n")
+	buf.WriteString(gutter + r.colored(colorGutter, "-->") + " " + e.Pos.String() + "\n")
+
+	if hasSource && e.Pos.Offset <= len(src) {
+		lineStart := bytes.LastIndexByte(src[:e.Pos.Offset], '\n') + 1
+		lineEnd := len(src)
+		if idx := bytes.IndexByte(src[e.Pos.Offset:], '\n'); idx >= 0 {
+			lineEnd = e.Pos.Offset + idx
+		}
+		text := strings.TrimSuffix(string(src[lineStart:lineEnd]), "\r")
+
+		// The caret is indented with the same whitespace characters as the
+		// source, so tabs are aligned.
+		var indent strings.Builder
+		for _, ch := range text[:e.Pos.Offset-lineStart] {
+			if ch == '\t' {
+				indent.WriteByte('\t')
+			} else {
+				indent.WriteByte(' ')
+			}
+		}
+		n := 1
+		if end := min(e.End.Offset, lineStart+len(text)); e.End.Line != 0 && end > e.Pos.Offset {
+			n = utf8.RuneCount(src[e.Pos.Offset:end])
+		}
+
+		buf.WriteString(gutter + " " + r.colored(colorGutter, "|") + "\n")
+		buf.WriteString(r.colored(colorGutter, line+" |") + " " + text + "\n")
+		buf.WriteString(gutter + " " + r.colored(colorGutter, "|") + " " + indent.String() + r.colored(colorError, strings.Repeat("^", n)) + "\n")
+	}
+
+	if e.Suggestion != "" {
+		buf.WriteString(gutter + " " + r.colored(colorGutter, "=") + " " + r.colored(colorHelp, "help") + ": " + e.Suggestion + "\n")
+	}
+	for _, pos := range e.Includes {
+		buf.WriteString(gutter + " " + r.colored(colorGutter, "=") + " " + r.colored(colorBold, "note") + ": included from " + pos.String() + "\n")
+	}
+}
+
+func (r *ErrorRenderer) source(filename string) ([]byte, bool) {
+	if filename == "" {
+		return nil, false
+	}
+	if src, has := r.sources[filename]; has {
+		return src, src != nil
+	}
+
+	readFile := r.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	src, err := readFile(filename)
+	if err != nil {
+		src = nil
+	}
+	if r.sources == nil {
+		r.sources = make(map[string][]byte)
+	}
+	r.sources[filename] = src
+	return src, src != nil
+}
+
+func (r *ErrorRenderer) colored(color string, s string) string {
+	if !r.Color {
+		return s
+	}
+	return fmt.Sprint(color, s, colorReset)
+}
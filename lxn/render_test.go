@@ -0,0 +1,79 @@
+package lxn
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/liblxn/lxnc/internal/errors"
+)
+
+func TestErrorRenderer(t *testing.T) {
+	files := map[string]string{
+		"a.lxn": "@include \"b.lxn\"\n",
+		"b.lxn": "first: text\nkey:\n\t${count:plural .one{one} .othr{many}}\n",
+	}
+	readFile := func(filename string) ([]byte, error) {
+		if content, has := files[filename]; has {
+			return []byte(content), nil
+		}
+		return nil, fmt.Errorf("file not found: %s", filename)
+	}
+
+	p := parser{readFile: readFile}
+	_, err := p.Parse("a.lxn", []byte(files["a.lxn"]))
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	err = append(err.(ErrorList), errors.New("plain error"))
+
+	var buf bytes.Buffer
+	r := ErrorRenderer{ReadFile: readFile}
+	if err := r.Render(&buf, err); err != nil {
+		t.Fatalf("unexpected render error: %v", err)
+	}
+
+	const expected = "error: invalid plural option: .othr\n" +
+		" --> b.lxn:3:26\n" +
+		"  |\n" +
+		"3 | \t${count:plural .one{one} .othr{many}}\n" +
+		"  | \t                         ^^^^^^^^^^^\n" +
+		"  = help: did you mean .other?\n" +
+		"  = note: included from a.lxn:1:0\n" +
+		"error: plural option .other required\n" +
+		" --> b.lxn:3:1\n" +
+		"  |\n" +
+		"3 | \t${count:plural .one{one} .othr{many}}\n" +
+		"  | \t^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^\n" +
+		"  = note: included from a.lxn:1:0\n" +
+		"error: plain error\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	r = ErrorRenderer{Color: true}
+	r.Render(&buf, Error{Err: errors.New("foo"), Pos: Pos{File: "unknown.lxn", Line: 1}})
+	if out := buf.String(); !strings.HasPrefix(out, colorError+"error"+colorReset) || strings.Contains(out, "|") {
+		t.Errorf("unexpected colored output: %q", out)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"othr", "did you mean .other?"},
+		{"OTHR", "did you mean .other?"},
+		{"on", "did you mean .one?"},
+		{"unknown", ""},
+		{"x", ""},
+	}
+	for _, test := range tests {
+		if s := suggest(".", test.name, pluralCategoryNames[:]...); s != test.expected {
+			t.Errorf("unexpected suggestion for %s: %q", test.name, s)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package lxn
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	msgpack "github.com/mprot/msgpack-go"
@@ -784,8 +785,83 @@ func (o *Plural) DecodeMsgpack(r *msgpack.Reader) error {
 	return nil
 }
 
+// PluralRange defines the plural category of a number range (e.g. "1-3 days"),
+// where the start and the end of the range have the given plural categories.
+type PluralRange struct {
+	Start  PluralCategory
+	End    PluralCategory
+	Result PluralCategory
+}
+
+// EncodeMsgpack implements the Encoder interface for PluralRange.
+func (o PluralRange) EncodeMsgpack(w *msgpack.Writer) (err error) {
+	if err = w.WriteMapHeader(3); err != nil {
+		return err
+	}
+	// Start
+	if err = w.WriteInt64(1); err != nil {
+		return err
+	}
+	if err = o.Start.EncodeMsgpack(w); err != nil {
+		return err
+	}
+	// End
+	if err = w.WriteInt64(2); err != nil {
+		return err
+	}
+	if err = o.End.EncodeMsgpack(w); err != nil {
+		return err
+	}
+	// Result
+	if err = w.WriteInt64(3); err != nil {
+		return err
+	}
+	if err = o.Result.EncodeMsgpack(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecodeMsgpack implements the Decoder interface for PluralRange.
+func (o *PluralRange) DecodeMsgpack(r *msgpack.Reader) error {
+	n, err := r.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		ord, err := r.ReadInt64()
+		if err != nil {
+			return err
+		}
+		switch ord {
+		case 1: // Start
+			if err = o.Start.DecodeMsgpack(r); err != nil {
+				return err
+			}
+		case 2: // End
+			if err = o.End.DecodeMsgpack(r); err != nil {
+				return err
+			}
+		case 3: // Result
+			if err = o.Result.DecodeMsgpack(r); err != nil {
+				return err
+			}
+		default:
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Locale holds the data which is necessary to format data in a region
 // specific format.
+//
+// The display names map locale ids to their names localized for this locale,
+// e.g. "de-AT" => "Deutsch (Österreich)" for a German locale. The direction
+// defines the order in which the characters of the locale are written. The
+// plural ranges define the plural categories of cardinal number ranges.
 type Locale struct {
 	ID              string
 	DecimalFormat   NumberFormat
@@ -793,11 +869,14 @@ type Locale struct {
 	PercentFormat   NumberFormat
 	CardinalPlurals []Plural
 	OrdinalPlurals  []Plural
+	DisplayNames    map[string]string
+	Direction       Direction
+	PluralRanges    []PluralRange
 }
 
 // EncodeMsgpack implements the Encoder interface for Locale.
 func (o Locale) EncodeMsgpack(w *msgpack.Writer) (err error) {
-	if err = w.WriteMapHeader(6); err != nil {
+	if err = w.WriteMapHeader(9); err != nil {
 		return err
 	}
 	// ID
@@ -852,6 +931,47 @@ func (o Locale) EncodeMsgpack(w *msgpack.Writer) (err error) {
 			return err
 		}
 	}
+	// DisplayNames
+	if err = w.WriteInt64(7); err != nil {
+		return err
+	}
+	if err = w.WriteMapHeader(len(o.DisplayNames)); err != nil {
+		return err
+	}
+	// The names are written in the order of their locale ids, so the encoding
+	// of a dictionary is deterministic.
+	oDisplayNamesKeys := make([]string, 0, len(o.DisplayNames))
+	for k := range o.DisplayNames {
+		oDisplayNamesKeys = append(oDisplayNamesKeys, k)
+	}
+	sort.Strings(oDisplayNamesKeys)
+	for _, k := range oDisplayNamesKeys {
+		if err = w.WriteString(k); err != nil {
+			return err
+		}
+		if err = w.WriteString(o.DisplayNames[k]); err != nil {
+			return err
+		}
+	}
+	// Direction
+	if err = w.WriteInt64(8); err != nil {
+		return err
+	}
+	if err = o.Direction.EncodeMsgpack(w); err != nil {
+		return err
+	}
+	// PluralRanges
+	if err = w.WriteInt64(9); err != nil {
+		return err
+	}
+	if err = w.WriteArrayHeader(len(o.PluralRanges)); err != nil {
+		return err
+	}
+	for _, e := range o.PluralRanges {
+		if err = e.EncodeMsgpack(w); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -913,6 +1033,44 @@ func (o *Locale) DecodeMsgpack(r *msgpack.Reader) error {
 					return err
 				}
 			}
+		case 7: // DisplayNames
+			oDisplayNamesLen, err := r.ReadMapHeader()
+			if err != nil {
+				return err
+			}
+			if o.DisplayNames == nil {
+				o.DisplayNames = make(map[string]string, oDisplayNamesLen)
+			}
+			for i := 0; i < oDisplayNamesLen; i++ {
+				var k string
+				if k, err = r.ReadString(); err != nil {
+					return err
+				}
+				var v string
+				if v, err = r.ReadString(); err != nil {
+					return err
+				}
+				o.DisplayNames[k] = v
+			}
+		case 8: // Direction
+			if err = o.Direction.DecodeMsgpack(r); err != nil {
+				return err
+			}
+		case 9: // PluralRanges
+			oPluralRangesLen, err := r.ReadArrayHeader()
+			if err != nil {
+				return err
+			}
+			if cap(o.PluralRanges) < oPluralRangesLen {
+				o.PluralRanges = make([]PluralRange, oPluralRangesLen)
+			} else {
+				o.PluralRanges = o.PluralRanges[:oPluralRangesLen]
+			}
+			for i := 0; i < oPluralRangesLen; i++ {
+				if err = o.PluralRanges[i].DecodeMsgpack(r); err != nil {
+					return err
+				}
+			}
 		default:
 			if err := r.Skip(); err != nil {
 				return err
@@ -922,20 +1080,61 @@ func (o *Locale) DecodeMsgpack(r *msgpack.Reader) error {
 	return nil
 }
 
+// Direction describes the order in which the characters of a locale are
+// written.
+type Direction int
+
+// Enumerators for Direction.
+const (
+	LeftToRight Direction = 0
+	RightToLeft Direction = 1
+)
+
+// EncodeMsgpack implements the Encoder interface for Direction.
+func (o Direction) EncodeMsgpack(w *msgpack.Writer) error {
+	return w.WriteInt(int(o))
+}
+
+// DecodeMsgpack implements the Decoder interface for Direction.
+func (o *Direction) DecodeMsgpack(r *msgpack.Reader) error {
+	val, err := r.ReadInt()
+	if err != nil {
+		return err
+	}
+	*o = Direction(val)
+	return nil
+}
+
 // Message holds the data for a single message. Each message consists of
 // a list of fragments which has to be concatenated to receive the final
 // message text. If the message does not contain any replacement variables,
 // there will only be a single string fragment.
+//
+// The origin holds the id of the locale the message was taken from. It is
+// only set, if the message was compiled with parent locale fallbacks.
+//
+// The notes hold the information for translators. They are only set in
+// catalogs which were compiled with translator notes. The origin and the notes
+// are left out of the encoded message if they are empty.
 type Message struct {
 	Section      string
 	Key          string
 	Text         []string
 	Replacements []Replacement
+	Origin       string
+	Notes        Notes
 }
 
 // EncodeMsgpack implements the Encoder interface for Message.
 func (o Message) EncodeMsgpack(w *msgpack.Writer) (err error) {
-	if err = w.WriteMapHeader(4); err != nil {
+	n := 4
+	if o.Origin != "" {
+		n++
+	}
+	if o.Notes != (Notes{}) {
+		n++
+	}
+	if err = w.WriteMapHeader(n); err != nil {
 		return err
 	}
 	// Section
@@ -976,6 +1175,24 @@ func (o Message) EncodeMsgpack(w *msgpack.Writer) (err error) {
 			return err
 		}
 	}
+	// Origin
+	if o.Origin != "" {
+		if err = w.WriteInt64(5); err != nil {
+			return err
+		}
+		if err = w.WriteString(o.Origin); err != nil {
+			return err
+		}
+	}
+	// Notes
+	if o.Notes != (Notes{}) {
+		if err = w.WriteInt64(6); err != nil {
+			return err
+		}
+		if err = o.Notes.EncodeMsgpack(w); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1029,6 +1246,86 @@ func (o *Message) DecodeMsgpack(r *msgpack.Reader) error {
 					return err
 				}
 			}
+		case 5: // Origin
+			if o.Origin, err = r.ReadString(); err != nil {
+				return err
+			}
+		case 6: // Notes
+			if err = o.Notes.DecodeMsgpack(r); err != nil {
+				return err
+			}
+		default:
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Notes holds the information for translators of a message. The description
+// explains the message, the context describes where the message is used, and
+// the maximum length limits the number of characters of the translated text.
+// A maximum length of zero means that the length is not limited.
+type Notes struct {
+	Description string
+	Context     string
+	MaxLength   int64
+}
+
+// EncodeMsgpack implements the Encoder interface for Notes.
+func (o Notes) EncodeMsgpack(w *msgpack.Writer) (err error) {
+	if err = w.WriteMapHeader(3); err != nil {
+		return err
+	}
+	// Description
+	if err = w.WriteInt64(1); err != nil {
+		return err
+	}
+	if err = w.WriteString(o.Description); err != nil {
+		return err
+	}
+	// Context
+	if err = w.WriteInt64(2); err != nil {
+		return err
+	}
+	if err = w.WriteString(o.Context); err != nil {
+		return err
+	}
+	// MaxLength
+	if err = w.WriteInt64(3); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(o.MaxLength); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecodeMsgpack implements the Decoder interface for Notes.
+func (o *Notes) DecodeMsgpack(r *msgpack.Reader) error {
+	n, err := r.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		ord, err := r.ReadInt64()
+		if err != nil {
+			return err
+		}
+		switch ord {
+		case 1: // Description
+			if o.Description, err = r.ReadString(); err != nil {
+				return err
+			}
+		case 2: // Context
+			if o.Context, err = r.ReadString(); err != nil {
+				return err
+			}
+		case 3: // MaxLength
+			if o.MaxLength, err = r.ReadInt64(); err != nil {
+				return err
+			}
 		default:
 			if err := r.Skip(); err != nil {
 				return err
@@ -1041,16 +1338,21 @@ func (o *Message) DecodeMsgpack(r *msgpack.Reader) error {
 // Replacement describes a variable piece of text in a message which will be replaced
 // during runtime. The key defines the variable's name which will be passed in. The type
 // contains more details about the particular replacement.
+//
+// If the replacement should be isolated, the replaced value has to be enclosed in
+// the bidi isolation characters FSI (U+2068) and PDI (U+2069), so the value's
+// direction does not affect the surrounding text.
 type Replacement struct {
 	Key     string
 	TextPos int
 	Type    ReplacementType
 	Details ReplacementDetails
+	Isolate bool
 }
 
 // EncodeMsgpack implements the Encoder interface for Replacement.
 func (o Replacement) EncodeMsgpack(w *msgpack.Writer) (err error) {
-	if err = w.WriteMapHeader(4); err != nil {
+	if err = w.WriteMapHeader(5); err != nil {
 		return err
 	}
 	// Key
@@ -1081,6 +1383,13 @@ func (o Replacement) EncodeMsgpack(w *msgpack.Writer) (err error) {
 	if err = o.Details.EncodeMsgpack(w); err != nil {
 		return err
 	}
+	// Isolate
+	if err = w.WriteInt64(5); err != nil {
+		return err
+	}
+	if err = w.WriteBool(o.Isolate); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1112,6 +1421,10 @@ func (o *Replacement) DecodeMsgpack(r *msgpack.Reader) error {
 			if err = o.Details.DecodeMsgpack(r); err != nil {
 				return err
 			}
+		case 5: // Isolate
+			if o.Isolate, err = r.ReadBool(); err != nil {
+				return err
+			}
 		default:
 			if err := r.Skip(); err != nil {
 				return err
@@ -1124,7 +1437,7 @@ func (o *Replacement) DecodeMsgpack(r *msgpack.Reader) error {
 // ReplacementDetails holds the details for particular replacements. The special
 // EmptyDetails branch indicates that there a no details for the replacement type.
 type ReplacementDetails struct {
-	Value interface{} // EmptyDetails, MoneyDetails, PluralDetails, or SelectDetails
+	Value interface{} // EmptyDetails, MoneyDetails, PluralDetails, SelectDetails, PluralRangeDetails, or MarkupDetails
 }
 
 // EncodeMsgpack implements the Encoder interface for ReplacementDetails.
@@ -1161,6 +1474,20 @@ func (o ReplacementDetails) EncodeMsgpack(w *msgpack.Writer) (err error) {
 		if err = v.EncodeMsgpack(w); err != nil {
 			return err
 		}
+	case PluralRangeDetails:
+		if err = w.WriteInt64(5); err != nil {
+			return err
+		}
+		if err = v.EncodeMsgpack(w); err != nil {
+			return err
+		}
+	case MarkupDetails:
+		if err = w.WriteInt64(6); err != nil {
+			return err
+		}
+		if err = v.EncodeMsgpack(w); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("invalid ReplacementDetails type %T", o.Value)
 	}
@@ -1201,6 +1528,18 @@ func (o *ReplacementDetails) DecodeMsgpack(r *msgpack.Reader) error {
 			return err
 		}
 		o.Value = v
+	case 5: // PluralRangeDetails
+		var v PluralRangeDetails
+		if err = v.DecodeMsgpack(r); err != nil {
+			return err
+		}
+		o.Value = v
+	case 6: // MarkupDetails
+		var v MarkupDetails
+		if err = v.DecodeMsgpack(r); err != nil {
+			return err
+		}
+		o.Value = v
 	default:
 		return fmt.Errorf("invalid ordinal %d for ReplacementDetails", ord)
 	}
@@ -1209,16 +1548,27 @@ func (o *ReplacementDetails) DecodeMsgpack(r *msgpack.Reader) error {
 
 // ReplacementType describes the type of a replacement. Each type contains the details
 // necessary to render the variable's value.
+//
+// A plural count replacement is only valid inside the variants of a plural. It
+// refers to the plural's variable and renders its value minus the plural's offset
+// with the decimal format.
+//
+// A markup replacement marks the position of a markup tag in the message text,
+// e.g. the start and the end of a link. The replacement key holds the name of the
+// tag, which can be mapped to a UI component by the client.
 type ReplacementType int
 
 // Enumerators for ReplacementType.
 const (
-	StringReplacement  ReplacementType = 1
-	NumberReplacement  ReplacementType = 2
-	PercentReplacement ReplacementType = 3
-	MoneyReplacement   ReplacementType = 4
-	PluralReplacement  ReplacementType = 5
-	SelectReplacement  ReplacementType = 6
+	StringReplacement      ReplacementType = 1
+	NumberReplacement      ReplacementType = 2
+	PercentReplacement     ReplacementType = 3
+	MoneyReplacement       ReplacementType = 4
+	PluralReplacement      ReplacementType = 5
+	SelectReplacement      ReplacementType = 6
+	PluralRangeReplacement ReplacementType = 7
+	PluralCountReplacement ReplacementType = 8
+	MarkupReplacement      ReplacementType = 9
 )
 
 // EncodeMsgpack implements the Encoder interface for ReplacementType.
@@ -1342,15 +1692,25 @@ func (o *MoneyDetails) DecodeMsgpack(r *msgpack.Reader) error {
 // PluralDetails contains the replacement details for plurals. Depending on the
 // variable, different text for each plural rule can be selected. It contains
 // the variants for the supported plural categories and custom overwrites.
+//
+// The offset is subtracted from the variable's value before the plural category
+// is determined. Custom overwrites are matched against the value without offset.
+//
+// Custom decimals are overwrites for numbers with visible fraction digits. They
+// are keyed by the number's decimal representation (e.g. "0.5" or "-1.0"), so a
+// value only matches, if its visible fraction digits are equal, i.e. 1.0 does not
+// match 1 or 1.00.
 type PluralDetails struct {
-	Type     PluralType
-	Variants map[PluralCategory]Message
-	Custom   map[int64]Message
+	Type           PluralType
+	Variants       map[PluralCategory]Message
+	Custom         map[int64]Message
+	Offset         int64
+	CustomDecimals map[string]Message
 }
 
 // EncodeMsgpack implements the Encoder interface for PluralDetails.
 func (o PluralDetails) EncodeMsgpack(w *msgpack.Writer) (err error) {
-	if err = w.WriteMapHeader(3); err != nil {
+	if err = w.WriteMapHeader(5); err != nil {
 		return err
 	}
 	// Type
@@ -1390,6 +1750,28 @@ func (o PluralDetails) EncodeMsgpack(w *msgpack.Writer) (err error) {
 			return err
 		}
 	}
+	// Offset
+	if err = w.WriteInt64(4); err != nil {
+		return err
+	}
+	if err = w.WriteInt64(o.Offset); err != nil {
+		return err
+	}
+	// CustomDecimals
+	if err = w.WriteInt64(5); err != nil {
+		return err
+	}
+	if err = w.WriteMapHeader(len(o.CustomDecimals)); err != nil {
+		return err
+	}
+	for k, v := range o.CustomDecimals {
+		if err = w.WriteString(k); err != nil {
+			return err
+		}
+		if err = v.EncodeMsgpack(w); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1447,6 +1829,29 @@ func (o *PluralDetails) DecodeMsgpack(r *msgpack.Reader) error {
 				}
 				o.Custom[k] = v
 			}
+		case 4: // Offset
+			if o.Offset, err = r.ReadInt64(); err != nil {
+				return err
+			}
+		case 5: // CustomDecimals
+			oCustomDecimalsLen, err := r.ReadMapHeader()
+			if err != nil {
+				return err
+			}
+			if o.CustomDecimals == nil {
+				o.CustomDecimals = make(map[string]Message, oCustomDecimalsLen)
+			}
+			for i := 0; i < oCustomDecimalsLen; i++ {
+				var k string
+				if k, err = r.ReadString(); err != nil {
+					return err
+				}
+				var v Message
+				if err = v.DecodeMsgpack(r); err != nil {
+					return err
+				}
+				o.CustomDecimals[k] = v
+			}
 		default:
 			if err := r.Skip(); err != nil {
 				return err
@@ -1537,3 +1942,157 @@ func (o *SelectDetails) DecodeMsgpack(r *msgpack.Reader) error {
 	}
 	return nil
 }
+
+// PluralRangeDetails contains the replacement details for plural ranges. The
+// replacement key defines the start of the range and End holds the key of the
+// range end. The variant is selected by the plural category of the range.
+type PluralRangeDetails struct {
+	End      string
+	Variants map[PluralCategory]Message
+}
+
+// EncodeMsgpack implements the Encoder interface for PluralRangeDetails.
+func (o PluralRangeDetails) EncodeMsgpack(w *msgpack.Writer) (err error) {
+	if err = w.WriteMapHeader(2); err != nil {
+		return err
+	}
+	// End
+	if err = w.WriteInt64(1); err != nil {
+		return err
+	}
+	if err = w.WriteString(o.End); err != nil {
+		return err
+	}
+	// Variants
+	if err = w.WriteInt64(2); err != nil {
+		return err
+	}
+	if err = w.WriteMapHeader(len(o.Variants)); err != nil {
+		return err
+	}
+	for k, v := range o.Variants {
+		if err = k.EncodeMsgpack(w); err != nil {
+			return err
+		}
+		if err = v.EncodeMsgpack(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeMsgpack implements the Decoder interface for PluralRangeDetails.
+func (o *PluralRangeDetails) DecodeMsgpack(r *msgpack.Reader) error {
+	n, err := r.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		ord, err := r.ReadInt64()
+		if err != nil {
+			return err
+		}
+		switch ord {
+		case 1: // End
+			if o.End, err = r.ReadString(); err != nil {
+				return err
+			}
+		case 2: // Variants
+			oVariantsLen, err := r.ReadMapHeader()
+			if err != nil {
+				return err
+			}
+			if o.Variants == nil {
+				o.Variants = make(map[PluralCategory]Message, oVariantsLen)
+			}
+			for i := 0; i < oVariantsLen; i++ {
+				var k PluralCategory
+				if err = k.DecodeMsgpack(r); err != nil {
+					return err
+				}
+				var v Message
+				if err = v.DecodeMsgpack(r); err != nil {
+					return err
+				}
+				o.Variants[k] = v
+			}
+		default:
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MarkupDetails contains the replacement details for markup tags. The kind
+// defines whether the replacement opens or closes a tag, or whether it is a
+// self-closing tag without any content.
+type MarkupDetails struct {
+	Kind MarkupKind
+}
+
+// EncodeMsgpack implements the Encoder interface for MarkupDetails.
+func (o MarkupDetails) EncodeMsgpack(w *msgpack.Writer) (err error) {
+	if err = w.WriteMapHeader(1); err != nil {
+		return err
+	}
+	// Kind
+	if err = w.WriteInt64(1); err != nil {
+		return err
+	}
+	if err = o.Kind.EncodeMsgpack(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecodeMsgpack implements the Decoder interface for MarkupDetails.
+func (o *MarkupDetails) DecodeMsgpack(r *msgpack.Reader) error {
+	n, err := r.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		ord, err := r.ReadInt64()
+		if err != nil {
+			return err
+		}
+		switch ord {
+		case 1: // Kind
+			if err = o.Kind.DecodeMsgpack(r); err != nil {
+				return err
+			}
+		default:
+			if err := r.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MarkupKind is an enumeration for the kinds of a markup tag.
+type MarkupKind int
+
+// Enumerators for MarkupKind.
+const (
+	OpenTag        MarkupKind = 0
+	CloseTag       MarkupKind = 1
+	SelfClosingTag MarkupKind = 2
+)
+
+// EncodeMsgpack implements the Encoder interface for MarkupKind.
+func (o MarkupKind) EncodeMsgpack(w *msgpack.Writer) error {
+	return w.WriteInt(int(o))
+}
+
+// DecodeMsgpack implements the Decoder interface for MarkupKind.
+func (o *MarkupKind) DecodeMsgpack(r *msgpack.Reader) error {
+	val, err := r.ReadInt()
+	if err != nil {
+		return err
+	}
+	*o = MarkupKind(val)
+	return nil
+}
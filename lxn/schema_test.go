@@ -0,0 +1,129 @@
+package lxn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	msgpack "github.com/mprot/msgpack-go"
+)
+
+// TestSchemaExtensions makes sure that the fields which lxnc adds to the
+// upstream lxn schema survive an encoding round trip. If schema.go is
+// regenerated from a schema without these fields, this test does not compile.
+func TestSchemaExtensions(t *testing.T) {
+	dic := Dictionary{
+		Locale: Locale{
+			ID:           "de-AT",
+			DisplayNames: map[string]string{"de": "Deutsch", "en": "Englisch"},
+			Direction:    RightToLeft,
+			PluralRanges: []PluralRange{{Start: One, End: Other, Result: Other}},
+		},
+		Messages: []Message{
+			{
+				Key:    "cart",
+				Text:   []string{"", " ", ""},
+				Origin: "de",
+				Notes:  Notes{Description: "cart status", Context: "header", MaxLength: 40},
+				Replacements: []Replacement{
+					{
+						Key:     "count",
+						TextPos: 0,
+						Type:    PluralReplacement,
+						Isolate: true,
+						Details: ReplacementDetails{Value: PluralDetails{
+							Type:           Cardinal,
+							Variants:       map[PluralCategory]Message{Other: {Text: []string{"items"}}},
+							Custom:         map[int64]Message{-1: {Text: []string{"minus one"}}},
+							Offset:         1,
+							CustomDecimals: map[string]Message{"1.5": {Text: []string{"one and a half"}}},
+						}},
+					},
+					{
+						Key:     "count",
+						TextPos: 1,
+						Type:    PluralRangeReplacement,
+						Details: ReplacementDetails{Value: PluralRangeDetails{
+							End:      "end",
+							Variants: map[PluralCategory]Message{Other: {Text: []string{"items"}}},
+						}},
+					},
+					{
+						Key:     "b",
+						TextPos: 2,
+						Type:    MarkupReplacement,
+						Details: ReplacementDetails{Value: MarkupDetails{Kind: SelfClosingTag}},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := msgpack.Encode(&buf, dic); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Dictionary
+	if err := msgpack.Decode(&buf, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded.Locale.DisplayNames, dic.Locale.DisplayNames) ||
+		decoded.Locale.Direction != dic.Locale.Direction ||
+		!reflect.DeepEqual(decoded.Locale.PluralRanges, dic.Locale.PluralRanges) {
+		t.Errorf("unexpected locale: %+v", decoded.Locale)
+	}
+	if !reflect.DeepEqual(decoded.Messages, dic.Messages) {
+		t.Errorf("unexpected messages: %+v", decoded.Messages)
+	}
+}
+
+func TestLocaleDisplayNamesDeterministic(t *testing.T) {
+	loc := Locale{
+		ID:           "de",
+		DisplayNames: map[string]string{"de": "Deutsch", "en": "Englisch", "fr": "Französisch", "it": "Italienisch", "nl": "Niederländisch"},
+	}
+
+	var expected []byte
+	for i := 0; i < 20; i++ {
+		var buf bytes.Buffer
+		if err := msgpack.Encode(&buf, loc); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if i == 0 {
+			expected = buf.Bytes()
+		} else if !bytes.Equal(buf.Bytes(), expected) {
+			t.Fatalf("different encoding in round %d", i)
+		}
+	}
+}
+
+func TestMessageOptionalFields(t *testing.T) {
+	tests := []struct {
+		msg     Message
+		entries byte
+	}{
+		{msg: Message{Key: "greeting", Text: []string{"Hello"}}, entries: 4},
+		{msg: Message{Key: "greeting", Text: []string{"Hallo"}, Origin: "de"}, entries: 5},
+		{msg: Message{Key: "greeting", Text: []string{"Hallo"}, Notes: Notes{Description: "greeting"}}, entries: 5},
+		{msg: Message{Key: "greeting", Text: []string{"Hallo"}, Origin: "de", Notes: Notes{MaxLength: 10}}, entries: 6},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := msgpack.Encode(&buf, test.msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if header := buf.Bytes()[0]; header != 0x80|test.entries {
+			t.Errorf("unexpected map header for %+v: %#x", test.msg, header)
+		}
+
+		var decoded Message
+		if err := msgpack.Decode(&buf, &decoded); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, test.msg) {
+			t.Errorf("unexpected message: %+v", decoded)
+		}
+	}
+}
@@ -28,9 +28,28 @@ func NewLocale(localeData locale.Locale) *Locale {
 		PercentFormat:   newNumberFormat(locale.PercentFormat(localeData)),
 		CardinalPlurals: newPlurals(locale.CardinalPlural(localeData)),
 		OrdinalPlurals:  newPlurals(locale.OrdinalPlural(localeData)),
+		Direction:       newDirection(localeData.Direction()),
+		PluralRanges:    newPluralRanges(locale.PluralRanges(localeData)),
 	}
 }
 
+// NewDisplayNames returns the display names of the given locales localized for the
+// given locale data. The names are keyed by the locale ids.
+func NewDisplayNames(localeData locale.Locale, subjects []locale.Locale) map[string]string {
+	names := make(map[string]string, len(subjects))
+	for _, subject := range subjects {
+		names[subject.String()] = locale.DisplayName(subject, localeData)
+	}
+	return names
+}
+
+func newDirection(dir locale.Direction) Direction {
+	if dir == locale.RightToLeft {
+		return RightToLeft
+	}
+	return LeftToRight
+}
+
 func newNumberFormat(nf locale.NumberFormat) NumberFormat {
 	symbols := nf.Symbols()
 	posAffixes := nf.PositiveAffixes()
@@ -73,6 +92,18 @@ func newPlurals(p locale.Plural) []Plural {
 	return res
 }
 
+func newPluralRanges(ranges []locale.PluralRange) []PluralRange {
+	res := make([]PluralRange, 0, len(ranges))
+	for _, rng := range ranges {
+		res = append(res, PluralRange{
+			Start:  PluralCategory(rng.Start),
+			End:    PluralCategory(rng.End),
+			Result: PluralCategory(rng.Result),
+		})
+	}
+	return res
+}
+
 func newPluralRule(r locale.PluralRule) PluralRule {
 	nranges := r.Ranges.Len()
 	ranges := make([]Range, nranges)
@@ -0,0 +1,53 @@
+package lxn
+
+import "strings"
+
+// replacementTypeNames holds the names of all replacement types.
+var replacementTypeNames = []string{
+	"string",
+	"number",
+	"percent",
+	"money",
+	"plural",
+	"select",
+	"pluralrange",
+	"tag",
+}
+
+// suggest returns a hint with the candidate which is closest to the given name,
+// e.g. "did you mean .other?" for ".othr". The prefix is prepended to the
+// candidate. If no candidate is close enough, an empty string is returned.
+func suggest(prefix string, name string, candidates ...string) string {
+	name = strings.ToLower(name)
+	best, bestDist := "", 3 // only suggest candidates with at most two edits
+	for _, c := range candidates {
+		if d := editDistance(name, c); d < bestDist && d < len(name) {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return "did you mean " + prefix + best + "?"
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		prev := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			prev, row[j] = row[j], min(row[j]+1, row[j-1]+1, prev+cost)
+		}
+	}
+	return row[len(rb)]
+}
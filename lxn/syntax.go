@@ -0,0 +1,188 @@
+package lxn
+
+import "github.com/liblxn/lxnc/internal/errors"
+
+// ParseFile parses the source of an lxn file and returns its syntax tree. If the
+// source contains syntax errors, an ErrorList is returned together with a tree of
+// all the declarations which could be parsed.
+//
+// The syntax tree only describes the structure of the source. Replacement types
+// and options are not validated before the messages are compiled.
+func ParseFile(filename string, src []byte) (*File, error) {
+	var p syntaxParser
+	return p.parseFile(filename, src)
+}
+
+type syntaxParser struct {
+	t    tokenizer
+	tok  token
+	errs ErrorList
+}
+
+func (p *syntaxParser) parseFile(filename string, src []byte) (*File, error) {
+	p.t.Init(filename, src)
+	p.errs.clear()
+	p.next() // scan initial token
+
+	file := &File{Name: filename}
+	file.StartPos = Pos{File: filename, Line: 1}
+	for p.tok.typ != eof {
+		switch p.tok.typ {
+		case comment, docComment:
+			file.Decls = append(file.Decls, &Comment{
+				Span: p.span(),
+				Text: p.tok.val,
+				Doc:  p.tok.typ == docComment,
+			})
+			p.next()
+		case sectionHeader:
+			file.Decls = append(file.Decls, &SectionDecl{Span: p.span(), Name: p.tok.val})
+			p.next()
+		case includeDirective:
+			file.Decls = append(file.Decls, &IncludeDecl{Span: p.span(), Path: p.tok.val})
+			p.next()
+		case messageKey:
+			file.Decls = append(file.Decls, p.parseMessageDecl())
+		default:
+			p.errorf("unexpected token %q", p.tok.val)
+			p.sync()
+		}
+	}
+
+	file.EndPos = p.t.pos
+	return file, p.errs.err()
+}
+
+func (p *syntaxParser) parseMessageDecl() *MessageDecl {
+	decl := &MessageDecl{Span: p.span(), Key: p.tok.val}
+	p.next()
+
+	if p.tok.typ == verbatimIndicator {
+		decl.Verbatim = true
+		decl.EndPos = p.tok.pos
+		p.next()
+	}
+
+	decl.Body = p.parseFragments()
+	if n := len(decl.Body); n != 0 {
+		decl.Indented = !decl.Verbatim && decl.Body[0].Start().Line > decl.StartPos.Line
+		decl.EndPos = decl.Body[n-1].End()
+	}
+	return decl
+}
+
+func (p *syntaxParser) parseFragments() []Fragment {
+	var fragments []Fragment
+	for {
+		switch p.tok.typ {
+		case messageText:
+			fragments = append(fragments, &Text{Span: p.span(), Value: p.tok.val})
+			p.next()
+		case messageNewline:
+			fragments = append(fragments, &LineBreak{Span: p.span()})
+			p.next()
+		case replacementStart:
+			fragments = append(fragments, p.parseReplacementExpr())
+		default:
+			return fragments
+		}
+	}
+}
+
+func (p *syntaxParser) parseReplacementExpr() *ReplacementExpr {
+	expr := &ReplacementExpr{Span: p.span(), Key: p.tok.val}
+	p.next()
+
+	if p.tok.typ == replacementType {
+		expr.Type = p.tok.val
+		p.next()
+	}
+
+	for p.tok.typ == replacementOptionStart {
+		opt := &Option{Span: p.span(), Name: p.tok.val}
+		nameEnd := p.tok.pos
+		p.next()
+
+		opt.Value = p.parseFragments()
+		expr.Options = append(expr.Options, opt)
+		if !p.expect(replacementOptionEnd) {
+			p.syncReplacement()
+			expr.EndPos = p.tok.start
+			return expr
+		}
+		opt.HasValue = p.tok.pos != nameEnd
+		opt.EndPos = p.tok.pos
+		p.next()
+	}
+
+	expr.EndPos = p.tok.pos
+	if !p.expect(replacementEnd) {
+		p.syncReplacement()
+		expr.EndPos = p.tok.start
+		return expr
+	}
+	p.next()
+	return expr
+}
+
+func (p *syntaxParser) span() Span {
+	return Span{StartPos: p.tok.start, EndPos: p.tok.pos}
+}
+
+// expect reports an error if the current token is not of the given type. The
+// token is not consumed.
+func (p *syntaxParser) expect(typ tokenType) bool {
+	if p.tok.typ != typ {
+		p.errorf("unexpected token %q", p.tok.val)
+		return false
+	}
+	return true
+}
+
+// sync skips all tokens up to the next declaration.
+func (p *syntaxParser) sync() {
+	for {
+		p.next()
+		switch p.tok.typ {
+		case eof, comment, docComment, sectionHeader, includeDirective, messageKey:
+			return
+		}
+	}
+}
+
+// syncReplacement skips all tokens up to the end of the current replacement. If
+// the replacement does not end before the next declaration, it stops there.
+func (p *syntaxParser) syncReplacement() {
+	depth := 0
+	for {
+		switch p.tok.typ {
+		case eof, comment, docComment, sectionHeader, includeDirective, messageKey:
+			return
+		case replacementStart:
+			depth++
+		case replacementEnd:
+			if depth == 0 {
+				p.next()
+				return
+			}
+			depth--
+		}
+		p.next()
+	}
+}
+
+func (p *syntaxParser) errorf(format string, args ...interface{}) {
+	p.errs.addSpan(errors.Newf(format, args...), p.tok.start, p.tok.pos)
+}
+
+// next scans the next token. Invalid tokens are reported and skipped, since the
+// tokenizer already resynchronized after the error.
+func (p *syntaxParser) next() {
+	for {
+		p.tok = p.t.Next()
+		if p.tok.typ != invalid {
+			return
+		}
+		p.errorf("%s", p.tok.val)
+	}
+}
@@ -0,0 +1,92 @@
+package lxn
+
+import "testing"
+
+func TestParseFile(t *testing.T) {
+	const input = "/// note\n[[sec]]\n@include \"other.lxn\"\nkey: Hi ${n:plural .one{x}}\n"
+
+	file, err := ParseFile("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(file.Decls) != 4 {
+		t.Fatalf("unexpected number of declarations: %d", len(file.Decls))
+	}
+
+	pos := func(line, col, off int) Pos {
+		return Pos{File: "test", Line: line, Column: col, Offset: off}
+	}
+	checkSpan := func(name string, n Node, start, end Pos) {
+		t.Helper()
+		if n.Start() != start {
+			t.Errorf("unexpected start of %s: %v", name, n.Start())
+		}
+		if n.End() != end {
+			t.Errorf("unexpected end of %s: %v", name, n.End())
+		}
+	}
+
+	doc, ok := file.Decls[0].(*Comment)
+	if !ok || !doc.Doc || doc.Text != " note" {
+		t.Errorf("unexpected doc comment: %#v", file.Decls[0])
+	}
+	checkSpan("doc comment", file.Decls[0], pos(1, 0, 0), pos(1, 8, 8))
+
+	if sec, ok := file.Decls[1].(*SectionDecl); !ok || sec.Name != "sec" {
+		t.Errorf("unexpected section: %#v", file.Decls[1])
+	}
+	checkSpan("section", file.Decls[1], pos(2, 0, 9), pos(2, 7, 16))
+
+	if incl, ok := file.Decls[2].(*IncludeDecl); !ok || incl.Path != "other.lxn" {
+		t.Errorf("unexpected include: %#v", file.Decls[2])
+	}
+	checkSpan("include", file.Decls[2], pos(3, 0, 17), pos(3, 20, 37))
+
+	msg, ok := file.Decls[3].(*MessageDecl)
+	if !ok {
+		t.Fatalf("unexpected message: %#v", file.Decls[3])
+	}
+	checkSpan("message", msg, pos(4, 0, 38), pos(4, 27, 65))
+	if msg.Key != "key" || msg.Indented || msg.Verbatim || len(msg.Body) != 2 {
+		t.Fatalf("unexpected message: %#v", msg)
+	}
+
+	if text, ok := msg.Body[0].(*Text); !ok || text.Value != "Hi " {
+		t.Errorf("unexpected text: %#v", msg.Body[0])
+	}
+	checkSpan("text", msg.Body[0], pos(4, 5, 43), pos(4, 8, 46))
+
+	expr, ok := msg.Body[1].(*ReplacementExpr)
+	if !ok || expr.Key != "n" || expr.Type != "plural" || len(expr.Options) != 1 {
+		t.Fatalf("unexpected replacement: %#v", msg.Body[1])
+	}
+	checkSpan("replacement", expr, pos(4, 8, 46), pos(4, 27, 65))
+
+	opt := expr.Options[0]
+	if opt.Name != "one" || !opt.HasValue || len(opt.Value) != 1 {
+		t.Errorf("unexpected option: %#v", opt)
+	}
+	checkSpan("option", opt, pos(4, 19, 57), pos(4, 26, 64))
+
+	checkSpan("file", file, pos(1, 0, 0), pos(5, 0, 66))
+}
+
+func TestParseFileWithErrors(t *testing.T) {
+	const input = "key: ${foo\n[[sec]]\nnext: text\n"
+
+	file, err := ParseFile("test", []byte(input))
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) == 0 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var keys []string
+	for _, decl := range file.Decls {
+		if msg, ok := decl.(*MessageDecl); ok {
+			keys = append(keys, msg.Key)
+		}
+	}
+	if len(keys) == 0 || keys[0] != "key" {
+		t.Errorf("unexpected messages: %v", keys)
+	}
+}
@@ -2,6 +2,7 @@ package lxn
 
 import (
 	"fmt"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -17,7 +18,11 @@ const (
 	invalid tokenType = iota
 	eof
 	sectionHeader
+	includeDirective
+	comment
+	docComment
 	messageKey
+	verbatimIndicator
 	messageText
 	messageNewline
 	replacementStart
@@ -28,59 +33,89 @@ const (
 )
 
 type token struct {
-	typ tokenType
-	val string
-	pos Pos
+	typ   tokenType
+	val   string
+	start Pos // start of the token
+	pos   Pos // end of the token
 }
 
+// tokenizer splits the source of an lxn file into tokens. The tokens are
+// pulled with Next, which scans the input declaration by declaration: all tokens
+// of the next declaration are buffered and handed out one by one. The values of
+// the tokens are slices of the input, so no text is copied.
 type tokenizer struct {
-	buf    []byte
-	off    int
-	tokens chan token
+	src string
+	off int
 
 	ch  rune
 	pos Pos
+
+	// unclosed is set when an unclosed replacement is detected. All enclosing
+	// replacements are closed without reporting further errors, and scanning
+	// continues with the next declaration.
+	unclosed bool
+
+	tokens []token // tokens of the current declaration
+	head   int     // index of the next token to return
 }
 
-func (t *tokenizer) Scan(filename string, input []byte) <-chan token {
-	t.buf = input
+// Init prepares the tokenizer for scanning the given input.
+func (t *tokenizer) Init(filename string, input []byte) {
+	t.src = string(input)
 	t.off = 0
-	t.tokens = make(chan token)
 	t.ch = '\n' // initialize pos correctly
 	t.pos = Pos{File: filename, Offset: -1}
+	t.unclosed = false
+	t.tokens = t.tokens[:0]
+	t.head = 0
 
 	t.next()
 	if t.ch == bom {
 		t.next()
 	}
+}
 
-	go func() {
-		defer close(t.tokens)
-		for {
-			t.skipNewlines()
+// Next returns the next token. At the end of the input, an eof token is
+// returned.
+func (t *tokenizer) Next() token {
+	for t.head == len(t.tokens) {
+		if t.ch == runeEOF || t.ch == utf8.RuneError {
+			return token{typ: eof, start: t.pos, pos: t.pos}
+		}
+		t.tokens = t.tokens[:0]
+		t.head = 0
+		t.scanDecl()
+	}
 
-			switch t.ch {
-			case runeEOF, utf8.RuneError:
-				return
-			case '[':
-				t.scanSectionHeader()
-			case '/':
-				t.scanComment()
-			default:
-				if t.skipNbSpaces() == 0 {
-					t.scanMessage()
-				} else if t.ch != runeEOF && t.skipNewlines() == 0 {
-					t.errorf("unexpected indentation")
-					t.nextLine()
-					return
-				}
-			}
+	tok := t.tokens[t.head]
+	t.head++
+	return tok
+}
+
+func (t *tokenizer) scanDecl() {
+	t.skipNewlines()
+	t.unclosed = false
+
+	switch t.ch {
+	case runeEOF, utf8.RuneError:
+	case '[':
+		t.scanSectionHeader()
+	case '@':
+		t.scanDirective()
+	case '/':
+		t.scanComment()
+	default:
+		if t.skipNbSpaces() == 0 {
+			t.scanMessage()
+		} else if t.ch != runeEOF && t.skipNewlines() == 0 {
+			t.errorf("unexpected indentation")
+			t.skipDecl()
 		}
-	}()
-	return t.tokens
+	}
 }
 
 func (t *tokenizer) scanSectionHeader() {
+	headerStart := t.pos
 	t.expect('[', '[')
 	t.skipNbSpaces()
 
@@ -90,39 +125,103 @@ func (t *tokenizer) scanSectionHeader() {
 		t.next()
 		t.skipIdent()
 	}
-	t.emit(sectionHeader, startPos)
+	name := t.src[startPos.Offset:t.pos.Offset]
 
 	t.skipNbSpaces()
 	t.expect(']', ']')
+	t.send(sectionHeader, name, headerStart)
 	t.skipNbSpaces()
 
 	if t.ch != runeEOF && t.skipNewlines() == 0 {
 		t.errorf("newline expected after section header")
+		t.nextLine()
+	}
+}
+
+func (t *tokenizer) scanDirective() {
+	directiveStart := t.pos
+	t.expect('@')
+
+	startPos := t.pos
+	t.skipIdent()
+	if name := t.src[startPos.Offset:t.pos.Offset]; name != "include" {
+		t.errorf("unknown directive: @%s", name)
+		t.nextLine()
+		return
+	}
+
+	t.skipNbSpaces()
+	t.expect('"')
+	startPos = t.pos
+	for t.ch != '"' && t.ch != '\n' && t.ch != runeEOF && t.ch != utf8.RuneError {
+		t.next()
+	}
+	path := t.src[startPos.Offset:t.pos.Offset]
+	t.expect('"')
+	t.send(includeDirective, path, directiveStart)
+	t.skipNbSpaces()
+
+	if t.ch != runeEOF && t.skipNewlines() == 0 {
+		t.errorf("newline expected after include directive")
 	}
 }
 
 func (t *tokenizer) scanComment() {
+	commentStart := t.pos
 	t.expect('/', '/')
+
+	// Comments starting with exactly three slashes are doc comments for the
+	// translators.
+	typ := comment
+	if t.ch == '/' && t.peek() != '/' {
+		typ = docComment
+		t.next()
+	}
+
+	startPos := t.pos
 	for {
 		switch t.ch {
-		case '\n':
-			t.next()
+		case '\r':
+			if t.peek() != '\n' {
+				break
+			}
+			fallthrough
+		case '\n', runeEOF, utf8.RuneError:
+			t.send(typ, t.src[startPos.Offset:t.pos.Offset], commentStart)
+			t.skipNewline()
 			return
 		case bom:
 			t.errorf("invalid byte order mark")
 			t.nextLine()
 			return
-		case runeEOF, utf8.RuneError:
-			return
 		}
 		t.next()
 	}
 }
 
 func (t *tokenizer) scanMessage() {
-	t.scanIdent(messageKey)
-	t.expect(':')
+	startPos := t.pos
+	t.skipIdent()
+	if startPos == t.pos {
+		t.errorf("unexpected token %q (message key expected)", t.ch)
+		t.skipDecl()
+		return
+	}
+	t.emit(messageKey, startPos)
+	if !t.expect(':') {
+		t.skipDecl()
+		return
+	}
 	t.skipNbSpaces()
+	if t.isVerbatimIndicator() {
+		startPos = t.pos
+		t.next() // skip '|'
+		t.emit(verbatimIndicator, startPos)
+		t.skipNbSpaces()
+		t.skipNewline()
+		t.scanVerbatimBlock()
+		return
+	}
 	t.skipNewlines()
 
 	if t.skipNbSpaces() != 0 {
@@ -134,6 +233,15 @@ func (t *tokenizer) scanMessage() {
 		}
 	}
 	t.scanMessageBlock()
+
+	// A closing brace outside of a replacement ends the block, so it is
+	// reported and skipped. A closing brace at the start of a line is left to
+	// the next declaration.
+	for t.ch == '}' && !t.unclosed && t.pos.Column != 0 {
+		t.errorf("unexpected token '}' (no replacement to close)")
+		t.next()
+		t.scanMessageBlock()
+	}
 }
 
 func (t *tokenizer) scanMessageBlock() {
@@ -144,6 +252,9 @@ func (t *tokenizer) scanMessageBlock() {
 		case '$':
 			if t.peek() == '{' {
 				t.scanMessageReplacement()
+				if t.unclosed {
+					return
+				}
 				break
 			}
 			fallthrough
@@ -157,12 +268,123 @@ func (t *tokenizer) scanMessageBlock() {
 				return
 			}
 		}
-		if startPos != t.pos {
+		// Indentation at the end of the input is trailing whitespace, which
+		// does not continue the message.
+		if startPos != t.pos && t.ch != runeEOF {
 			t.emit(messageNewline, startPos)
 		}
 	}
 }
 
+// isVerbatimIndicator reports whether the current character starts a verbatim
+// block, i.e. it is a '|' which is only followed by whitespaces up to the end
+// of the line.
+func (t *tokenizer) isVerbatimIndicator() bool {
+	if t.ch != '|' {
+		return false
+	}
+	for i := t.off; i < len(t.src); i++ {
+		switch t.src[i] {
+		case ' ', '\t', '\r':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// scanVerbatimBlock scans the lines of a verbatim block. The indentation of the
+// first line defines the indentation of the block, which is stripped from each
+// line. Line breaks and any additional indentation are preserved and emitted as
+// message text. The block ends with the first non-empty line which is not
+// indented.
+func (t *tokenizer) scanVerbatimBlock() {
+	indent := -1
+	newlines := 0 // pending line breaks
+	for {
+		n := 0
+		for (indent < 0 || n < indent) && (t.ch == '\t' || unicode.Is(unicode.Zs, t.ch)) {
+			t.next()
+			n++
+		}
+
+		startPos := t.pos
+		t.skipNbSpaces()
+		switch {
+		case t.ch == runeEOF || t.ch == utf8.RuneError:
+			return
+		case t.skipNewline():
+			if indent >= 0 {
+				newlines++
+			}
+			continue
+		case n == 0:
+			return
+		case indent < 0:
+			indent = n
+		case n < indent:
+			t.errorf("insufficient indentation in verbatim block")
+			t.nextLine()
+			continue
+		}
+
+		if newlines != 0 {
+			t.tokens = append(t.tokens, token{
+				typ:   messageText,
+				val:   strings.Repeat("\n", newlines),
+				start: startPos,
+				pos:   startPos,
+			})
+		}
+		t.scanVerbatimLine(startPos)
+		if t.unclosed {
+			return
+		}
+		t.skipNewline()
+		newlines = 1
+	}
+}
+
+func (t *tokenizer) scanVerbatimLine(startPos Pos) {
+	emitText := func() {
+		if startPos != t.pos {
+			t.emit(messageText, startPos)
+		}
+	}
+
+	for {
+		switch t.ch {
+		case bom:
+			emitText()
+			t.errorf("invalid byte order mark")
+			t.next()
+			startPos = t.pos
+			continue
+		case utf8.RuneError, runeEOF, '\n':
+			emitText()
+			return
+		case '\r':
+			if t.peek() == '\n' {
+				emitText()
+				return
+			}
+		case '$':
+			if t.peek() == '{' {
+				emitText()
+				t.scanMessageReplacement()
+				if t.unclosed {
+					return
+				}
+				startPos = t.pos
+				continue
+			}
+		}
+		t.next()
+	}
+}
+
 func (t *tokenizer) scanMessageText() {
 	startPos := t.pos
 	defer func() {
@@ -189,49 +411,152 @@ func (t *tokenizer) scanMessageText() {
 }
 
 func (t *tokenizer) scanMessageReplacement() {
+	replStart := t.pos
 	t.expect('$', '{')
 
-	t.scanIdent(replacementStart)
+	startPos := t.pos
+	switch t.ch {
+	case '#':
+		// The plural count placeholder ${#} has no identifier.
+		t.next()
+	case '@':
+		// A message reference ${@section.key} is scanned as a whole.
+		t.next()
+		t.skipIdent()
+		for t.ch == '.' {
+			t.next()
+			t.skipIdent()
+		}
+	default:
+		t.skipIdent()
+	}
+	t.send(replacementStart, t.src[startPos.Offset:t.pos.Offset], replStart)
 	if t.ch == ':' {
 		t.next() // skip ':'
 		t.scanIdent(replacementType)
+
+		// The content of a markup tag ${tag:tag{content}} directly follows
+		// the type and is emitted as an option without name.
+		if t.ch == '{' {
+			t.emit(replacementOptionStart, t.pos)
+			t.next()
+			t.scanMessageBlock()
+			endPos := t.pos
+			closed := t.closeBrace(replStart)
+			t.send(replacementOptionEnd, "", endPos)
+			if !closed {
+				t.send(replacementEnd, "", t.pos)
+				return
+			}
+		}
 	}
 
 	t.skipNbSpaces()
 	if t.skipNewlines() != 0 && t.skipNbSpaces() == 0 {
-		t.errorf("unclosed replacement ('}' expected)")
+		t.unclosedError(replStart)
+		t.unclosed = true
+		t.send(replacementEnd, "", t.pos)
 		return
 	}
 
 	for t.ch == '.' {
+		optionStart := t.pos
 		t.next() // skip '.'
 
 		startPos := t.pos
 		if t.ch == '[' {
 			t.next() // skip '['
 			t.skipIdent()
+			for t.ch == '.' {
+				t.next()
+				t.skipIdent()
+			}
 			t.expect(']')
 		} else {
 			t.skipIdent()
 		}
 
-		t.emit(replacementOptionStart, startPos)
+		t.send(replacementOptionStart, t.src[startPos.Offset:t.pos.Offset], optionStart)
+		endPos := t.pos
 		if t.ch == '{' {
 			t.next()
 			t.scanMessageBlock()
-			t.expect('}')
+			endPos = t.pos
+			if !t.closeBrace(replStart) {
+				t.send(replacementOptionEnd, "", endPos)
+				t.send(replacementEnd, "", t.pos)
+				return
+			}
 		}
-		t.emit(replacementOptionEnd, t.pos)
+		t.send(replacementOptionEnd, "", endPos)
 
 		t.skipNbSpaces()
 		if t.skipNewlines() != 0 && t.skipNbSpaces() == 0 {
-			t.errorf("unclosed replacement ('}' expected)")
+			t.unclosedError(replStart)
+			t.unclosed = true
+			t.send(replacementEnd, "", t.pos)
 			return
 		}
 	}
 
-	t.expect('}')
-	t.emit(replacementEnd, t.pos)
+	endPos := t.pos
+	t.closeBrace(replStart)
+	t.send(replacementEnd, "", endPos)
+}
+
+// closeBrace expects the closing brace of a replacement or an option value and
+// reports whether it was found. If there is any other text, it is reported and
+// skipped up to the closing brace. If the replacement, which starts at the given
+// position, is not closed up to the end of the message, the tokenizer is marked
+// as unclosed.
+func (t *tokenizer) closeBrace(replStart Pos) bool {
+	switch {
+	case t.unclosed:
+		return false
+	case t.ch == '}':
+		t.next()
+		return true
+	case t.ch == runeEOF || t.ch == utf8.RuneError:
+		t.expect('}')
+	case t.pos.Column == 0:
+		// The message block ended with a line that is not indented.
+		t.unclosedError(replStart)
+	default:
+		t.expect('}')
+		if t.skipToBrace() {
+			return true
+		}
+	}
+	t.unclosed = true
+	return false
+}
+
+// skipToBrace skips all characters up to and including the next closing brace
+// which is not part of a nested replacement. It stops at the end of the message
+// and reports whether the closing brace was found.
+func (t *tokenizer) skipToBrace() bool {
+	depth := 0
+	for {
+		switch t.ch {
+		case runeEOF, utf8.RuneError:
+			return false
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				t.next()
+				return true
+			}
+			depth--
+		case '\n':
+			t.next()
+			if t.ch != '\t' && !unicode.Is(unicode.Zs, t.ch) && t.ch != '\n' && t.ch != '\r' {
+				return false
+			}
+			continue
+		}
+		t.next()
+	}
 }
 
 func (t *tokenizer) scanIdent(typ tokenType) {
@@ -270,7 +595,19 @@ func (t *tokenizer) skipNewlines() int {
 	return n
 }
 
-func (t *tokenizer) expect(chars ...rune) {
+// skipNewline skips a single line break and reports whether there was one.
+func (t *tokenizer) skipNewline() bool {
+	switch {
+	case t.ch == '\r' && t.peek() == '\n':
+		t.next() // skip '\r'
+	case t.ch != '\n':
+		return false
+	}
+	t.next() // skip '\n'
+	return true
+}
+
+func (t *tokenizer) expect(chars ...rune) bool {
 	for _, ch := range chars {
 		if t.ch != ch {
 			switch t.ch {
@@ -281,28 +618,72 @@ func (t *tokenizer) expect(chars ...rune) {
 			default:
 				t.errorf("unexpected token %q (%q expected)", t.ch, ch)
 			}
-			return
+			return false
 		}
 		t.next()
 	}
+	return true
 }
 
+// errorf sends an error token, which spans the current character.
 func (t *tokenizer) errorf(msg string, args ...interface{}) {
 	if len(args) != 0 {
 		msg = fmt.Sprintf(msg, args...)
 	}
-	t.tokens <- token{
-		typ: invalid,
-		val: msg,
-		pos: t.pos,
+
+	end := t.pos
+	if t.ch != runeEOF && t.ch != utf8.RuneError && t.ch != '\n' && t.ch != '\r' {
+		end.advance(t.ch)
 	}
+	t.tokens = append(t.tokens, token{
+		typ:   invalid,
+		val:   msg,
+		start: t.pos,
+		pos:   end,
+	})
+}
+
+// unclosedError sends the error for an unclosed replacement, which spans the
+// opening '${' at the given position.
+func (t *tokenizer) unclosedError(replStart Pos) {
+	end := replStart
+	end.advance('$')
+	end.advance('{')
+	t.tokens = append(t.tokens, token{
+		typ:   invalid,
+		val:   "unclosed replacement ('}' expected)",
+		start: replStart,
+		pos:   end,
+	})
 }
 
+// emit sends a token whose value is the input from the start position up to the
+// current position.
 func (t *tokenizer) emit(typ tokenType, start Pos) {
-	t.tokens <- token{
-		typ: typ,
-		val: string(t.buf[start.Offset:t.pos.Offset]),
-		pos: t.pos,
+	t.send(typ, t.src[start.Offset:t.pos.Offset], start)
+}
+
+// send sends a token which spans the input from the start position up to the
+// current position. The invalid characters skipped by next are removed from the
+// value.
+func (t *tokenizer) send(typ tokenType, val string, start Pos) {
+	t.tokens = append(t.tokens, token{
+		typ:   typ,
+		val:   stripInvalid(val),
+		start: start,
+		pos:   t.pos,
+	})
+}
+
+// skipDecl skips the rest of the current line and all following lines which are
+// indented, so scanning continues with the next declaration.
+func (t *tokenizer) skipDecl() {
+	t.nextLine()
+	for t.ch != runeEOF {
+		if t.skipNewlines() == 0 && t.skipNbSpaces() == 0 {
+			return
+		}
+		t.nextLine()
 	}
 }
 
@@ -317,25 +698,61 @@ func (t *tokenizer) next() {
 		return
 	}
 
+	t.pos.advance(t.ch)
 	for {
-		t.pos.advance(t.ch)
-		t.ch = t.peek()
-		switch t.ch {
-		case 0:
-			t.errorf("invalid character nul")
-		case utf8.RuneError:
-			t.errorf("invalid encoding")
-		case runeEOF:
+		ch, n := utf8.DecodeRuneInString(t.src[t.off:])
+		switch {
+		case n == 0:
+			t.ch = runeEOF
 			return
+		case ch == 0:
+			t.skipInvalid("invalid character nul", n)
+		case ch == utf8.RuneError:
+			t.skipInvalid("invalid encoding", n)
 		default:
-			t.off += utf8.RuneLen(t.ch)
+			t.ch = ch
+			t.off += n
 			return
 		}
 	}
 }
 
+// skipInvalid reports the invalid character of the given size at the current
+// position and skips it.
+func (t *tokenizer) skipInvalid(msg string, size int) {
+	end := t.pos
+	end.Column++
+	end.Offset += size
+	t.tokens = append(t.tokens, token{
+		typ:   invalid,
+		val:   msg,
+		start: t.pos,
+		pos:   end,
+	})
+	t.pos = end
+	t.off += size
+}
+
+// stripInvalid removes the nul characters and invalid encodings from s.
+func stripInvalid(s string) string {
+	if strings.IndexByte(s, 0) < 0 && strings.IndexRune(s, utf8.RuneError) < 0 {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for len(s) > 0 {
+		ch, n := utf8.DecodeRuneInString(s)
+		if ch != 0 && ch != utf8.RuneError {
+			b.WriteString(s[:n])
+		}
+		s = s[n:]
+	}
+	return b.String()
+}
+
 func (t *tokenizer) peek() rune {
-	ch, n := utf8.DecodeRune(t.buf[t.off:])
+	ch, n := utf8.DecodeRuneInString(t.src[t.off:])
 	if ch == utf8.RuneError && n == 0 {
 		ch = runeEOF
 	}
@@ -1,7 +1,9 @@
 package lxn
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -21,12 +23,26 @@ func TestTokenizer(t *testing.T) {
 			tokens: []token{},
 		},
 		{
-			input:  "// comment one\n\n//comment two",
-			tokens: []token{},
+			input: "// comment one\n\n//comment two",
+			tokens: []token{
+				newToken(comment, " comment one"),
+				newToken(comment, "comment two"),
+			},
 		},
 		{
-			input:  "// comment one\r\n\r\n//comment two",
-			tokens: []token{},
+			input: "// comment one\r\n\r\n//comment two",
+			tokens: []token{
+				newToken(comment, " comment one"),
+				newToken(comment, "comment two"),
+			},
+		},
+		{
+			input: "/// doc comment\n//// comment\n///@maxlen 20",
+			tokens: []token{
+				newToken(docComment, " doc comment"),
+				newToken(comment, "// comment"),
+				newToken(docComment, "@maxlen 20"),
+			},
 		},
 		{
 			input: "[[section.header]]",
@@ -40,12 +56,64 @@ func TestTokenizer(t *testing.T) {
 				newToken(sectionHeader, "section.header"),
 			},
 		},
+		{
+			input: "@include \"common/units.lxn\"\n[[section]]",
+			tokens: []token{
+				newToken(includeDirective, "common/units.lxn"),
+				newToken(sectionHeader, "section"),
+			},
+		},
 		{
 			input: "message-key:",
 			tokens: []token{
 				newToken(messageKey, "message-key"),
 			},
 		},
+		{
+			input: "message-key: |  \n\t\tline one\n\n\t\t  indented ${param}\r\n\t\tline } three\n\nnext-key: text",
+			tokens: []token{
+				newToken(messageKey, "message-key"),
+				newToken(verbatimIndicator, "|"),
+				newToken(messageText, "line one"),
+				newToken(messageText, "\n\n"),
+				newToken(messageText, "  indented "),
+				newToken(replacementStart, "param"),
+				newToken(replacementEnd, ""),
+				newToken(messageText, "\n"),
+				newToken(messageText, "line } three"),
+				newToken(messageKey, "next-key"),
+				newToken(messageText, "text"),
+			},
+		},
+		{
+			input: "message-key: read ${link:tag{our ${b:tag{terms}}}}${br:tag}",
+			tokens: []token{
+				newToken(messageKey, "message-key"),
+				newToken(messageText, "read "),
+				newToken(replacementStart, "link"),
+				newToken(replacementType, "tag"),
+				newToken(replacementOptionStart, ""),
+				newToken(messageText, "our "),
+				newToken(replacementStart, "b"),
+				newToken(replacementType, "tag"),
+				newToken(replacementOptionStart, ""),
+				newToken(messageText, "terms"),
+				newToken(replacementOptionEnd, ""),
+				newToken(replacementEnd, ""),
+				newToken(replacementOptionEnd, ""),
+				newToken(replacementEnd, ""),
+				newToken(replacementStart, "br"),
+				newToken(replacementType, "tag"),
+				newToken(replacementEnd, ""),
+			},
+		},
+		{
+			input: "message-key: | text",
+			tokens: []token{
+				newToken(messageKey, "message-key"),
+				newToken(messageText, "| text"),
+			},
+		},
 		{
 			input: "message-key:   message-text",
 			tokens: []token{
@@ -230,6 +298,41 @@ func TestTokenizer(t *testing.T) {
 				newToken(replacementEnd, ""),
 			},
 		},
+		{
+			input: "message-key:\n\t${replacement:type\n\t.opt{${#} inner-text}}",
+			tokens: []token{
+				newToken(messageKey, "message-key"),
+				newToken(replacementStart, "replacement"),
+				newToken(replacementType, "type"),
+				newToken(replacementOptionStart, "opt"),
+				newToken(replacementStart, "#"),
+				newToken(replacementEnd, ""),
+				newToken(messageText, " inner-text"),
+				newToken(replacementOptionEnd, ""),
+				newToken(replacementEnd, ""),
+			},
+		},
+		{
+			input: "message-key:\n\t${replacement:type\n\t.[-0.50]{inner-text}}",
+			tokens: []token{
+				newToken(messageKey, "message-key"),
+				newToken(replacementStart, "replacement"),
+				newToken(replacementType, "type"),
+				newToken(replacementOptionStart, "[-0.50]"),
+				newToken(messageText, "inner-text"),
+				newToken(replacementOptionEnd, ""),
+				newToken(replacementEnd, ""),
+			},
+		},
+		{
+			input: "message-key:\n\tmessage-text ${@section.sub.key}",
+			tokens: []token{
+				newToken(messageKey, "message-key"),
+				newToken(messageText, "message-text "),
+				newToken(replacementStart, "@section.sub.key"),
+				newToken(replacementEnd, ""),
+			},
+		},
 		{
 			input: "message-key:\n\t${replacement:type\n\t.opt1{}\n\t.opt2{}}",
 			tokens: []token{
@@ -247,12 +350,12 @@ func TestTokenizer(t *testing.T) {
 
 	for _, test := range tests {
 		var tk tokenizer
-		tokens := tk.Scan("test", []byte(test.input))
+		tk.Init("test", []byte(test.input))
 
 		for _, expected := range test.tokens {
-			tok, ok := <-tokens
+			tok := tk.Next()
 			switch {
-			case !ok:
+			case tok.typ == eof:
 				t.Errorf("unexpected eof for %q", test.input)
 			case tok.typ == invalid:
 				t.Errorf("unexpected error for %q: %v", test.input, tok.val)
@@ -304,6 +407,22 @@ func TestTokenizerWithErrors(t *testing.T) {
 			input:  "[[section]]  f:",
 			errmsg: "newline expected after section header",
 		},
+		{
+			input:  "@import \"file.lxn\"",
+			errmsg: "unknown directive: @import",
+		},
+		{
+			input:  "@include \"file.lxn",
+			errmsg: "unexpected eof ('\"' expected)",
+		},
+		{
+			input:  "@include \"file.lxn\" f:",
+			errmsg: "newline expected after include directive",
+		},
+		{
+			input:  "message-key: |\n\t\tline one\n\tline two",
+			errmsg: "insufficient indentation in verbatim block",
+		},
 		{
 			input:  "/ comment",
 			errmsg: "unexpected token ' ' ('/' expected)",
@@ -324,12 +443,25 @@ func TestTokenizerWithErrors(t *testing.T) {
 			input:  "message-key:\n\t${foo.opt{}",
 			errmsg: "unexpected eof ('}' expected)",
 		},
+		{
+			input:  "message-key: foo \x00 bar",
+			errmsg: "invalid character nul",
+		},
+		{
+			input:  "message-key: foo \xff bar",
+			errmsg: "invalid encoding",
+		},
+		{
+			input:  "\xc3",
+			errmsg: "invalid encoding",
+		},
 	}
 
 	for _, test := range tests {
 		tk := tokenizer{}
+		tk.Init("test", []byte(test.input))
 		token := token{typ: eof}
-		for tok := range tk.Scan("test", []byte(test.input)) {
+		for tok := tk.Next(); tok.typ != eof; tok = tk.Next() {
 			if tok.typ == invalid {
 				token = tok
 			}
@@ -344,6 +476,168 @@ func TestTokenizerWithErrors(t *testing.T) {
 	}
 }
 
+func TestTokenizerRecovery(t *testing.T) {
+	type test struct {
+		input  string
+		errors []string
+		keys   []string // message keys which are scanned after recovering
+	}
+
+	tests := [...]test{
+		{
+			input:  "key: a\n  indented\n  lines\nnext: b\n  more\n",
+			errors: nil,
+			keys:   []string{"key", "next"},
+		},
+		{
+			input:  "  indented\n  lines\nnext: b\n\t\tbad: indentation\n",
+			errors: []string{"unexpected indentation"},
+			keys:   []string{"next"},
+		},
+		{
+			input:  "key: a } b\nnext: c\n",
+			errors: []string{"unexpected token '}' (no replacement to close)"},
+			keys:   []string{"key", "next"},
+		},
+		{
+			input:  "} stray\n\tcontinued\nnext: c\n",
+			errors: []string{"unexpected token '}' (message key expected)"},
+			keys:   []string{"next"},
+		},
+		{
+			input:  "key: ${foo bar} text\nnext: c\n",
+			errors: []string{"unexpected token 'b' ('}' expected)"},
+			keys:   []string{"key", "next"},
+		},
+		{
+			input:  "key: ${foo:plural .one{x}junk {y}} text\nnext: c\n",
+			errors: []string{"unexpected token 'j' ('}' expected)"},
+			keys:   []string{"key", "next"},
+		},
+		{
+			input:  "key:\n\t${foo\nnext: ${a:plural .one{x\nlast: y\n",
+			errors: []string{"unclosed replacement ('}' expected)", "unclosed replacement ('}' expected)"},
+			keys:   []string{"key", "next", "last"},
+		},
+		{
+			input:  "key: |\n\tline ${foo\nnext: c\n",
+			errors: []string{"unclosed replacement ('}' expected)"},
+			keys:   []string{"key", "next"},
+		},
+		{
+			input:  "@import \"x\"\n[[sec]] x\nmissing\n\tcolon\nkey: ${a:tag{b}\nnext: c",
+			errors: []string{"unknown directive: @import", "newline expected after section header", "unexpected newline (':' expected)", "unclosed replacement ('}' expected)"},
+			keys:   []string{"missing", "key", "next"},
+		},
+		{
+			input:  "key: a\x00b ${c\xff}\n[[sec\xfe]]\nnext: \xc3\n",
+			errors: []string{"invalid character nul", "invalid encoding", "invalid encoding", "invalid encoding"},
+			keys:   []string{"key", "next"},
+		},
+	}
+
+	for _, test := range tests {
+		var (
+			tk     tokenizer
+			errors []string
+			keys   []string
+		)
+		tk.Init("test", []byte(test.input))
+		for tok := tk.Next(); tok.typ != eof; tok = tk.Next() {
+			switch tok.typ {
+			case invalid:
+				errors = append(errors, tok.val)
+			case messageKey:
+				keys = append(keys, tok.val)
+			}
+		}
+
+		if !reflect.DeepEqual(errors, test.errors) {
+			t.Errorf("unexpected errors for %q: %q", test.input, errors)
+		}
+		if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("unexpected keys for %q: %q", test.input, keys)
+		}
+	}
+}
+
+func TestTokenizerUnclosedReplacementPos(t *testing.T) {
+	// The error is reported at the opening '${' of the unclosed replacement.
+	tests := []struct {
+		input        string
+		line, column int
+	}{
+		{input: "key:\n\t${foo\nnext: c", line: 2, column: 1},
+		{input: "key: ${foo.opt{}\nnext: c", line: 1, column: 5},
+		{input: "key: ${a:plural .one{x\nnext: c", line: 1, column: 5},
+		{input: "key: ${a:plural .one{${b}\nnext: c", line: 1, column: 5},
+		{input: "key: ${a:tag{b}\nnext: c", line: 1, column: 5},
+		{input: "key: |\n\tline ${foo\nnext: c", line: 2, column: 6},
+	}
+
+	for _, test := range tests {
+		var tk tokenizer
+		tk.Init("test", []byte(test.input))
+
+		var errs []token
+		for tok := tk.Next(); tok.typ != eof; tok = tk.Next() {
+			if tok.typ == invalid {
+				errs = append(errs, tok)
+			}
+		}
+
+		switch {
+		case len(errs) != 1:
+			t.Errorf("unexpected errors for %q: %+v", test.input, errs)
+		case errs[0].start.Line != test.line || errs[0].start.Column != test.column:
+			t.Errorf("unexpected error position for %q: %s", test.input, errs[0].start)
+		case errs[0].pos.Offset-errs[0].start.Offset != 2:
+			t.Errorf("unexpected error span for %q: %s-%s", test.input, errs[0].start, errs[0].pos)
+		}
+	}
+}
+
+func TestTokenizerPositions(t *testing.T) {
+	const input = "[[sec]]\nkey: a ${b:plural .one{x}}\n"
+
+	type span struct {
+		typ        tokenType
+		start, end int // offsets
+	}
+	expected := []span{
+		{sectionHeader, 0, 7},
+		{messageKey, 8, 11},
+		{messageText, 13, 15},
+		{replacementStart, 15, 18},
+		{replacementType, 19, 25},
+		{replacementOptionStart, 26, 30},
+		{messageText, 31, 32},
+		{replacementOptionEnd, 32, 33},
+		{replacementEnd, 33, 34},
+	}
+
+	var tk tokenizer
+	tk.Init("test", []byte(input))
+	for _, exp := range expected {
+		tok := tk.Next()
+		switch {
+		case tok.typ == eof:
+			t.Fatalf("unexpected eof")
+		case tok.typ != exp.typ:
+			t.Errorf("unexpected token type: %s (%s expected)", tokenTypeName(tok.typ), tokenTypeName(exp.typ))
+		case tok.start.Offset != exp.start || tok.pos.Offset != exp.end:
+			t.Errorf("unexpected span for %s: %d-%d (%d-%d expected)", tokenTypeName(tok.typ), tok.start.Offset, tok.pos.Offset, exp.start, exp.end)
+		}
+	}
+
+	tk.Init("test", []byte("[section]]"))
+	for tok := tk.Next(); tok.typ != eof; tok = tk.Next() {
+		if tok.typ == invalid && (tok.start.Column != 1 || tok.pos.Column != 2) {
+			t.Errorf("unexpected error span: %v-%v", tok.start, tok.pos)
+		}
+	}
+}
+
 func newToken(typ tokenType, val string) token {
 	return token{typ: typ, val: val}
 }
@@ -356,6 +650,14 @@ func tokenTypeName(typ tokenType) string {
 		return "eof"
 	case sectionHeader:
 		return "sectionHeader"
+	case includeDirective:
+		return "includeDirective"
+	case comment:
+		return "comment"
+	case docComment:
+		return "docComment"
+	case verbatimIndicator:
+		return "verbatimIndicator"
 	case messageKey:
 		return "messageKey"
 	case messageText:
@@ -376,3 +678,32 @@ func tokenTypeName(typ tokenType) string {
 		return fmt.Sprintf("tokenType%d", typ)
 	}
 }
+
+func BenchmarkTokenizer(b *testing.B) {
+	input := benchmarkInput("section", 1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var tk tokenizer
+		tk.Init("bench.lxn", input)
+		for tk.Next().typ != eof {
+		}
+	}
+}
+
+// benchmarkInput returns the source of an lxn file with n messages of each
+// kind. The messages are placed in sections with the given name prefix.
+func benchmarkInput(section string, n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "[[%s-%d]]\n", section, i)
+		fmt.Fprintf(&buf, "/// A translator note for message %d.\n", i)
+		fmt.Fprintf(&buf, "plain-%d: Some plain message text without any replacements.\n", i)
+		fmt.Fprintf(&buf, "greeting-%d: Hello ${name}, you have ${count:number} new messages.\n", i)
+		fmt.Fprintf(&buf, "plural-%d:\n\t${count:plural .one{one file} .other{${#} files}}\n\twere deleted.\n", i)
+		fmt.Fprintf(&buf, "select-%d: ${gender:select .[female]{her} .[male]{his} .default{female}} car\n", i)
+		fmt.Fprintf(&buf, "markup-%d: Read the ${link:tag{terms of service}} and ${@%s-%d.plain-%d}.\n", i, section, i, i)
+		fmt.Fprintf(&buf, "verbatim-%d: |\n\tfirst line\n\t  indented line with ${name}\n\n", i)
+	}
+	return buf.Bytes()
+}
@@ -2,6 +2,9 @@ package lxn
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 )
 
 type Validator interface {
@@ -36,5 +39,128 @@ func ValidateMessages(messages []Message, v Validator) {
 			}
 		}
 		keys[msg.Key] = struct{}{}
+
+		if maxlen := msg.Notes.MaxLength; maxlen > 0 {
+			if n := maxTextLength(msg); int64(n) > maxlen {
+				warnf("message %q exceeds the maximum length of %d characters (%d characters)", msg.Key, maxlen, n)
+			}
+		}
+
+		validateReplacements(msg, msg.Replacements, warnf)
+	}
+}
+
+// ValidateMarkup compares the markup tags of the messages with the markup tags
+// of the corresponding source messages, which are usually the messages of the
+// source locale. Messages without a source message are not validated.
+func ValidateMarkup(messages []Message, source []Message, v Validator) {
+	type messageID struct {
+		section string
+		key     string
+	}
+
+	sourceTags := make(map[messageID][]string, len(source))
+	for _, msg := range source {
+		sourceTags[messageID{section: msg.Section, key: msg.Key}] = markupTags(msg)
+	}
+
+	for _, msg := range messages {
+		expected, has := sourceTags[messageID{section: msg.Section, key: msg.Key}]
+		if !has {
+			continue
+		}
+		if tags := markupTags(msg); !reflect.DeepEqual(tags, expected) && v != nil {
+			v.Warn(fmt.Sprintf("markup tags of message %q do not match the source message (%s instead of %s)",
+				msg.Key, formatTags(tags), formatTags(expected)))
+		}
+	}
+}
+
+// markupTags returns the sorted names of all markup tags used in the message
+// and its variants.
+func markupTags(msg Message) []string {
+	set := make(map[string]struct{})
+	forEachFragment(msg, func(fragment Message) {
+		for _, repl := range fragment.Replacements {
+			if _, isMarkup := repl.Details.Value.(MarkupDetails); isMarkup {
+				set[repl.Key] = struct{}{}
+			}
+		}
+	})
+
+	tags := make([]string, 0, len(set))
+	for tag := range set {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "no tags"
+	}
+	return strings.Join(tags, ", ")
+}
+
+// forEachFragment calls fn for the message and all of its (nested) variants.
+func forEachFragment(msg Message, fn func(Message)) {
+	fn(msg)
+	for _, repl := range msg.Replacements {
+		switch details := repl.Details.Value.(type) {
+		case PluralDetails:
+			for _, variant := range details.Variants {
+				forEachFragment(variant, fn)
+			}
+			for _, custom := range details.Custom {
+				forEachFragment(custom, fn)
+			}
+			for _, custom := range details.CustomDecimals {
+				forEachFragment(custom, fn)
+			}
+		case PluralRangeDetails:
+			for _, variant := range details.Variants {
+				forEachFragment(variant, fn)
+			}
+		case SelectDetails:
+			for _, c := range details.Cases {
+				forEachFragment(c, fn)
+			}
+		}
+	}
+}
+
+func validateReplacements(msg Message, replacements []Replacement, warnf func(string, ...any)) {
+	for _, repl := range replacements {
+		switch details := repl.Details.Value.(type) {
+		case PluralDetails:
+			if details.Type == Ordinal {
+				decimals := make([]string, 0, len(details.CustomDecimals))
+				for dec := range details.CustomDecimals {
+					decimals = append(decimals, dec)
+				}
+				sort.Strings(decimals)
+				for _, dec := range decimals {
+					warnf("decimal plural option .[%s] never matches the ordinal %q in message %q", dec, repl.Key, msg.Key)
+				}
+			}
+			for _, variant := range details.Variants {
+				validateReplacements(msg, variant.Replacements, warnf)
+			}
+			for _, custom := range details.Custom {
+				validateReplacements(msg, custom.Replacements, warnf)
+			}
+			for _, custom := range details.CustomDecimals {
+				validateReplacements(msg, custom.Replacements, warnf)
+			}
+		case PluralRangeDetails:
+			for _, variant := range details.Variants {
+				validateReplacements(msg, variant.Replacements, warnf)
+			}
+		case SelectDetails:
+			for _, c := range details.Cases {
+				validateReplacements(msg, c.Replacements, warnf)
+			}
+		}
 	}
 }
@@ -0,0 +1,78 @@
+package lxn
+
+import (
+	"reflect"
+	"testing"
+)
+
+type warnings []string
+
+func (w *warnings) Warn(msg string) {
+	*w = append(*w, msg)
+}
+
+func TestValidateMessages(t *testing.T) {
+	messages := []Message{
+		{Key: "key"},
+		{Key: "key"},
+		{Section: "section", Key: "key"},
+		{Key: "short", Text: []string{"fits"}, Notes: Notes{MaxLength: 4}},
+		{Key: "long", Text: []string{"too long"}, Notes: Notes{MaxLength: 4}},
+		{
+			Key: "ordinal",
+			Replacements: []Replacement{
+				{
+					Key:  "n",
+					Type: PluralReplacement,
+					Details: ReplacementDetails{Value: PluralDetails{
+						Type: Ordinal,
+						CustomDecimals: map[string]Message{
+							"1.5": {Key: "1.5"},
+							"0.5": {Key: "0.5"},
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	expected := warnings{
+		`duplicate message key "key"`,
+		`message "long" exceeds the maximum length of 4 characters (8 characters)`,
+		`decimal plural option .[0.5] never matches the ordinal "n" in message "ordinal"`,
+		`decimal plural option .[1.5] never matches the ordinal "n" in message "ordinal"`,
+	}
+
+	var w warnings
+	ValidateMessages(messages, &w)
+	if !reflect.DeepEqual(w, expected) {
+		t.Errorf("unexpected warnings: %q", w)
+	}
+}
+
+func TestValidateMarkup(t *testing.T) {
+	markup := func(key string, kind MarkupKind) Replacement {
+		return Replacement{Key: key, Type: MarkupReplacement, Details: ReplacementDetails{Value: MarkupDetails{Kind: kind}}}
+	}
+
+	source := []Message{
+		{Key: "terms", Replacements: []Replacement{markup("link", OpenTag), markup("link", CloseTag)}},
+		{Key: "plain"},
+	}
+	messages := []Message{
+		{Key: "terms", Replacements: []Replacement{markup("a", OpenTag), markup("a", CloseTag)}},
+		{Key: "plain", Replacements: []Replacement{markup("b", OpenTag), markup("i", CloseTag)}},
+		{Key: "unknown", Replacements: []Replacement{markup("br", SelfClosingTag)}},
+	}
+
+	expected := warnings{
+		`markup tags of message "terms" do not match the source message (a instead of link)`,
+		`markup tags of message "plain" do not match the source message (b, i instead of no tags)`,
+	}
+
+	var w warnings
+	ValidateMarkup(messages, source, &w)
+	if !reflect.DeepEqual(w, expected) {
+		t.Errorf("unexpected warnings: %q", w)
+	}
+}
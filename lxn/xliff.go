@@ -0,0 +1,207 @@
+package lxn
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type xliffDocument struct {
+	XMLName xml.Name  `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+	Version string    `xml:"version,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	SourceLanguage string           `xml:"source-language,attr"`
+	Datatype       string           `xml:"datatype,attr"`
+	Original       string           `xml:"original,attr"`
+	Units          []xliffTransUnit `xml:"body>trans-unit"`
+}
+
+type xliffTransUnit struct {
+	ID       string      `xml:"id,attr"`
+	MaxWidth int64       `xml:"maxwidth,attr,omitempty"`
+	SizeUnit string      `xml:"size-unit,attr,omitempty"`
+	Source   string      `xml:"source"`
+	Notes    []xliffNote `xml:"note"`
+}
+
+type xliffNote struct {
+	From string `xml:"from,attr"`
+	Text string `xml:",chardata"`
+}
+
+var pluralCategoryNames = [...]string{
+	Zero:  "zero",
+	One:   "one",
+	Two:   "two",
+	Few:   "few",
+	Many:  "many",
+	Other: "other",
+}
+
+// WriteXLIFF writes the messages as an XLIFF 1.2 document with the given locale
+// as the source language. Each message becomes a translation unit identified by
+// its section and key. The source text is written in lxn syntax, so replacements
+// are preserved for the translators. The translator notes are written as notes
+// of the translation unit, and the maximum length as the unit's maximum width.
+func WriteXLIFF(w io.Writer, localeID string, messages []Message) error {
+	doc := xliffDocument{
+		Version: "1.2",
+		File: xliffFile{
+			SourceLanguage: localeID,
+			Datatype:       "plaintext",
+			Original:       "lxn",
+			Units:          make([]xliffTransUnit, 0, len(messages)),
+		},
+	}
+
+	for _, msg := range messages {
+		unit := xliffTransUnit{
+			ID:     MessageName(msg.Section, msg.Key),
+			Source: messageSource(msg),
+		}
+		if msg.Notes.MaxLength > 0 {
+			unit.MaxWidth = msg.Notes.MaxLength
+			unit.SizeUnit = "char"
+		}
+		if msg.Notes.Description != "" {
+			unit.Notes = append(unit.Notes, xliffNote{From: "description", Text: msg.Notes.Description})
+		}
+		if msg.Notes.Context != "" {
+			unit.Notes = append(unit.Notes, xliffNote{From: "context", Text: msg.Notes.Context})
+		}
+		doc.File.Units = append(doc.File.Units, unit)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// messageSource returns the text of a message in lxn syntax.
+func messageSource(msg Message) string {
+	var b strings.Builder
+	writeMessageSource(&b, msg)
+	return b.String()
+}
+
+func writeMessageSource(b *strings.Builder, msg Message) {
+	replIdx := 0
+	for i := 0; i <= len(msg.Text); i++ {
+		for ; replIdx < len(msg.Replacements) && msg.Replacements[replIdx].TextPos <= i; replIdx++ {
+			writeReplacementSource(b, msg.Replacements[replIdx])
+		}
+		if i < len(msg.Text) {
+			b.WriteString(msg.Text[i])
+		}
+	}
+}
+
+func writeReplacementSource(b *strings.Builder, repl Replacement) {
+	option := func(name string, msg Message) {
+		b.WriteString(" .")
+		b.WriteString(name)
+		b.WriteByte('{')
+		writeMessageSource(b, msg)
+		b.WriteByte('}')
+	}
+	textOption := func(name string, text string) {
+		option(name, Message{Text: []string{text}})
+	}
+	categories := func(variants map[PluralCategory]Message) {
+		for cat, name := range pluralCategoryNames {
+			if variant, has := variants[PluralCategory(cat)]; has {
+				option(name, variant)
+			}
+		}
+	}
+
+	if repl.Type == PluralCountReplacement {
+		b.WriteString("${#}")
+		return
+	}
+	if markup, isMarkup := repl.Details.Value.(MarkupDetails); isMarkup {
+		switch markup.Kind {
+		case OpenTag:
+			b.WriteString("${" + repl.Key + ":tag{")
+		case CloseTag:
+			b.WriteString("}}")
+		default:
+			b.WriteString("${" + repl.Key + ":tag}")
+		}
+		return
+	}
+
+	b.WriteString("${")
+	b.WriteString(repl.Key)
+	switch details := repl.Details.Value.(type) {
+	case MoneyDetails:
+		b.WriteString(":money")
+		textOption("currency", details.Currency)
+
+	case PluralDetails:
+		b.WriteString(":plural")
+		if details.Type == Ordinal {
+			b.WriteString(" .ordinal")
+		}
+		if details.Offset != 0 {
+			textOption("offset", strconv.FormatInt(details.Offset, 10))
+		}
+		custom := make([]int64, 0, len(details.Custom))
+		for n := range details.Custom {
+			custom = append(custom, n)
+		}
+		sort.Slice(custom, func(i, j int) bool { return custom[i] < custom[j] })
+		for _, n := range custom {
+			option("["+strconv.FormatInt(n, 10)+"]", details.Custom[n])
+		}
+		decimals := make([]string, 0, len(details.CustomDecimals))
+		for dec := range details.CustomDecimals {
+			decimals = append(decimals, dec)
+		}
+		sort.Strings(decimals)
+		for _, dec := range decimals {
+			option("["+dec+"]", details.CustomDecimals[dec])
+		}
+		categories(details.Variants)
+
+	case PluralRangeDetails:
+		b.WriteString(":pluralrange")
+		textOption("to", details.End)
+		categories(details.Variants)
+
+	case SelectDetails:
+		b.WriteString(":select")
+		if details.Fallback != "" {
+			textOption("default", details.Fallback)
+		}
+		cases := make([]string, 0, len(details.Cases))
+		for c := range details.Cases {
+			cases = append(cases, c)
+		}
+		sort.Strings(cases)
+		for _, c := range cases {
+			option("["+c+"]", details.Cases[c])
+		}
+
+	default:
+		switch repl.Type {
+		case NumberReplacement:
+			b.WriteString(":number")
+		case PercentReplacement:
+			b.WriteString(":percent")
+		}
+	}
+	b.WriteByte('}')
+}
@@ -0,0 +1,49 @@
+package lxn
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteXLIFF(t *testing.T) {
+	const input = `
+/// Greets the user on the start page.
+/// @context start page header
+/// @maxlen 30
+greeting: Hello ${name}, you have ${count:plural .[0]{no items} .one{${#} item} .other{${#} items}}
+
+[[cart]]
+total: Total: ${amount:money .currency{EUR}} & ${link:tag{more}}${br:tag}
+`
+
+	var p parser
+	messages, err := p.Parse("test", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	const expected = `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+  <file source-language="en" datatype="plaintext" original="lxn">
+    <body>
+      <trans-unit id="greeting" maxwidth="30" size-unit="char">
+        <source>Hello ${name}, you have ${count:plural .[0]{no items} .one{${#} item} .other{${#} items}}</source>
+        <note from="description">Greets the user on the start page.</note>
+        <note from="context">start page header</note>
+      </trans-unit>
+      <trans-unit id="cart.total">
+        <source>Total: ${amount:money .currency{EUR}} &amp; ${link:tag{more}}${br:tag}</source>
+      </trans-unit>
+    </body>
+  </file>
+</xliff>
+`
+
+	var buf bytes.Buffer
+	if err := WriteXLIFF(&buf, "en", messages); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := buf.String(); s != expected {
+		t.Errorf("unexpected xliff document:\n%s", s)
+	}
+}
@@ -2,16 +2,23 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 
-	msgpack "github.com/mprot/msgpack-go"
-
+	"github.com/liblxn/lxnc/internal/lsp"
 	"github.com/liblxn/lxnc/locale"
 	"github.com/liblxn/lxnc/lxn"
 )
 
-const targetExt = ".lxnc"
+const (
+	version   = "0.1.0"
+	sourceExt = ".lxn"
+	targetExt = ".lxnc"
+)
 
 type compilation struct {
 	files []string
@@ -22,100 +29,493 @@ type compilation struct {
 func main() {
 	opts := parseCommandLine()
 
-	var cat *lxn.Catalog
+	args := opts.inputFiles
+	switch opts.command {
+	case compileCommand, exportCommand, checkCommand:
+		files, err := expandInputs(opts.inputFiles, splitPatterns(opts.include), splitPatterns(opts.exclude))
+		if err != nil {
+			fatalErr(err)
+		}
+		opts.inputFiles = files
+	}
+
+	switch {
+	case opts.cacheDir != "":
+		c, err := newBuildCache(opts.cacheDir)
+		if err != nil {
+			fatalErr(err)
+		}
+		cache = c
+	case opts.cacheStats:
+		fatalf("cache statistics require a cache directory (see --cache)")
+	}
+
 	switch opts.command {
-	case compileCommand:
-		cat = compile(opts.locale, opts.inputFiles)
-	case bundleCommand:
-		cat = bundle(opts.inputFiles)
+	case compileCommand, exportCommand, bundleCommand, linkCommand:
+		if opts.watch {
+			watch(opts, args)
+			return
+		}
+		if _, err := build(opts); err != nil {
+			fatalErr(err)
+		}
+		printCacheStats(opts)
+	case checkCommand:
+		check(opts)
+	case formatCommand:
+		format(opts)
+	case lspCommand:
+		var server lsp.Server
+		if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+			fatalf("%v", err)
+		}
 	default:
 		fatalf("unknown command %q", opts.command)
 	}
+}
 
-	if cat == nil {
-		return
+// build compiles, exports, bundles, or links the input files and writes the
+// output file. It returns the name of the output file, which is empty if there are no
+// input files.
+func build(opts options) (string, error) {
+	var (
+		cat *lxn.Catalog
+		err error
+	)
+	switch {
+	case opts.command == bundleCommand:
+		cat, err = bundle(opts)
+	case opts.command == linkCommand:
+		if opts.catalog {
+			return "", errors.New("the link command only writes dictionaries")
+		}
+		var displayNames []string
+		cat, displayNames, err = link(opts)
+		if opts.withNames == "" {
+			opts.withNames = strings.Join(displayNames, ",")
+		}
+	case opts.fallbackDir != "":
+		cat, err = compileWithFallbacks(opts.locale, opts.fallbackDir, opts.inputFiles)
+	default:
+		cat, err = compile(opts.locale, opts.inputFiles)
+	}
+	if err != nil || cat == nil {
+		return "", err
 	}
 
 	lxn.ValidateMessages(cat.Messages, warner{})
+	if opts.sourceFiles != "" {
+		source, err := lxn.CompileMessages(strings.Split(opts.sourceFiles, ",")...)
+		if err != nil {
+			return "", err
+		}
+		lxn.ValidateMarkup(cat.Messages, source, warner{})
+	}
+
+	output := opts.outputFile
+	switch {
+	case output != "":
+	case opts.command == exportCommand:
+		output = cat.LocaleID + ".xlf"
+	default:
+		output = cat.LocaleID + targetExt
+	}
 
+	// The output is reused if neither the inputs nor the options changed.
+	var key string
+	if cache != nil {
+		key = cache.outputKey(opts)
+		if data, ok := cache.output(key); ok {
+			return output, writeOutput(output, data)
+		}
+	}
+
+	var data []byte
+	if opts.command == exportCommand {
+		data, err = export(cat)
+	} else {
+		data, err = encode(cat, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := writeOutput(output, data); err != nil {
+		return "", err
+	}
+	if cache != nil {
+		cache.putOutput(key, data)
+	}
+	return output, nil
+}
+
+// encode encodes the catalog into a catalog or a dictionary file.
+func encode(cat *lxn.Catalog, opts options) ([]byte, error) {
 	var out bytes.Buffer
 	if opts.catalog {
-		if err := msgpack.Encode(&out, cat); err != nil {
-			fatalf("error encoding catalog: %v", err)
+		switch {
+		case opts.withNames != "":
+			return nil, errors.New("display names are not supported for catalogs")
+		case opts.bidiIsolate:
+			return nil, errors.New("bidi isolation is not supported for catalogs")
+		}
+		if !opts.withNotes {
+			lxn.StripNotes(cat.Messages)
+		}
+		if err := lxn.WriteCatalog(&out, cat, version); err != nil {
+			return nil, fmt.Errorf("error encoding catalog: %v", err)
 		}
 	} else {
 		loc, err := locale.New(cat.LocaleID)
 		if err != nil {
-			fatalf("%v", err)
+			return nil, err
 		}
 
+		if opts.withNotes {
+			return nil, errors.New("translator notes are not supported for dictionaries")
+		}
+		lxn.StripNotes(cat.Messages)
+
 		dic := lxn.NewDictionary(loc, cat.Messages)
-		if err := msgpack.Encode(&out, dic); err != nil {
-			fatalf("error encoding dictionary: %v", err)
+		if opts.bidiIsolate && dic.Locale.Direction == lxn.RightToLeft {
+			lxn.IsolateReplacements(dic.Messages)
+		}
+		if opts.withNames != "" {
+			locales, err := parseLocales(opts.withNames)
+			if err != nil {
+				return nil, err
+			}
+			dic.Locale.DisplayNames = lxn.NewDisplayNames(loc, locales)
 		}
+		if err := lxn.WriteDictionary(&out, dic, version); err != nil {
+			return nil, fmt.Errorf("error encoding dictionary: %v", err)
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func export(cat *lxn.Catalog) ([]byte, error) {
+	var out bytes.Buffer
+	if err := lxn.WriteXLIFF(&out, cat.LocaleID, cat.Messages); err != nil {
+		return nil, fmt.Errorf("error exporting messages: %v", err)
 	}
+	return out.Bytes(), nil
+}
 
-	output := opts.outputFile
-	if output == "" {
-		output = cat.LocaleID + ".lxnc"
+// writeOutput writes the output file atomically: the data is written to a
+// temporary file, which replaces the output file afterwards. So readers, e.g. a
+// development server, never see a partially written file. If the output file
+// already has the same content, it is left untouched.
+func writeOutput(output string, data []byte) error {
+	if current, err := os.ReadFile(output); err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+
+	// The temporary file is only accessible by the owner, so the permissions
+	// of an existing output file are kept.
+	perm := fs.FileMode(0644)
+	if fi, err := os.Stat(output); err == nil {
+		perm = fi.Mode().Perm()
 	}
 
-	err := os.WriteFile(output, out.Bytes(), 0666)
+	f, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+".tmp-")
 	if err != nil {
-		fatalf("error writing %s: %v", output, err)
+		return fmt.Errorf("error writing %s: %v", output, err)
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), perm)
 	}
+	if err == nil {
+		err = os.Rename(f.Name(), output)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("error writing %s: %v", output, err)
+	}
+	return nil
 }
 
-func compile(localeID string, inputFiles []string) *lxn.Catalog {
+func compile(localeID string, inputFiles []string) (*lxn.Catalog, error) {
 	switch {
 	case localeID == "":
-		fatalf("missing locale")
+		return nil, errors.New("missing locale")
 	case len(inputFiles) == 0:
-		return nil
+		return nil, nil
 	}
 
 	loc, err := locale.New(localeID)
 	if err != nil {
-		fatalf("%v", err)
+		return nil, err
 	}
 
-	cat, err := lxn.CompileCatalog(loc, inputFiles...)
+	messages, err := parseMessages(inputFiles)
 	if err != nil {
-		fatalf("%v", err)
+		return nil, err
+	}
+	if err := lxn.ResolveReferences(messages); err != nil {
+		return nil, err
 	}
-	return cat
+	return lxn.NewCatalog(loc, messages), nil
 }
 
-func bundle(inputFiles []string) *lxn.Catalog {
-	if len(inputFiles) == 0 {
-		return nil
+func compileWithFallbacks(localeID string, sourceDir string, inputFiles []string) (*lxn.Catalog, error) {
+	if localeID == "" {
+		return nil, errors.New("missing locale")
 	}
 
-	locale := ""
-	messages := make([]lxn.Message, 0, 128)
-	for _, inputFile := range inputFiles {
-		f, err := os.Open(inputFile)
+	loc, err := locale.New(localeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []lxn.FallbackSource
+	for {
+		files, err := localeSourceFiles(sourceDir, loc)
 		if err != nil {
-			fatalf("%v", err)
+			return nil, err
+		}
+		if len(sources) == 0 {
+			files = append(files, inputFiles...)
+		}
+
+		// References are resolved after merging, so messages are able to
+		// reference messages inherited from an ancestor.
+		messages, err := parseMessages(files)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, lxn.FallbackSource{
+			LocaleID: loc.String(),
+			Messages: messages,
+		})
+
+		parent := loc.Parent()
+		if parent == loc {
+			break
+		}
+		loc = parent
+	}
+
+	messages, inherited := lxn.ResolveFallbacks(sources)
+	if err := lxn.ResolveReferences(messages); err != nil {
+		return nil, err
+	}
+	for _, msg := range inherited {
+		fmt.Fprintf(os.Stderr, "inherited %s from %s\n", lxn.MessageName(msg.Section, msg.Key), msg.LocaleID)
+	}
+
+	return &lxn.Catalog{
+		LocaleID: sources[0].LocaleID,
+		Messages: messages,
+	}, nil
+}
+
+// parseMessages parses the translation files in parallel. If a cache is used,
+// the messages of unchanged files are taken from the cache.
+func parseMessages(files []string) ([]lxn.Message, error) {
+	if cache == nil {
+		return lxn.ParseMessagesConcurrent(0, files...)
+	}
+	return cache.parseMessages(files)
+}
+
+// localeSourceFiles returns the translation files in the locale's subdirectory of
+// the given source directory. The root locale can either be stored in an 'und' or
+// a 'root' subdirectory.
+func localeSourceFiles(sourceDir string, loc locale.Locale) ([]string, error) {
+	dirs := []string{loc.String()}
+	if loc.Parent() == loc {
+		dirs = []string{"und", "root"}
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		matches, err := filepath.Glob(filepath.Join(sourceDir, dir, "*"+sourceExt))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// parseLocales parses a comma-separated list of locale tags.
+func parseLocales(tags string) ([]locale.Locale, error) {
+	var locales []locale.Locale
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		loc, err := locale.New(tag)
+		if err != nil {
+			return nil, err
+		}
+		locales = append(locales, loc)
+	}
+	return locales, nil
+}
+
+// bundle merges the catalog files. Messages which are defined by more than one
+// catalog are resolved with the conflict policy, and every overridden message is
+// listed in a report.
+func bundle(opts options) (*lxn.Catalog, error) {
+	if len(opts.inputFiles) == 0 {
+		return nil, nil
+	}
+
+	m, err := newMerger(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		locale    string
+		first     string // first input file
+		firstCLDR string // CLDR version of the first input file
+	)
+	for _, inputFile := range opts.inputFiles {
+		data, err := readInput(inputFile)
+		if err != nil {
+			return nil, err
 		}
 
-		cat := &lxn.Catalog{}
-		err = msgpack.Decode(f, cat)
-		f.Close()
+		c, err := lxn.ReadContainer(bytes.NewReader(data))
 		switch {
 		case err != nil:
-			fatalf("unable to decode catalog file %q", inputFile)
-		case locale != "" && cat.LocaleID != locale:
-			fatalf("multiple locales detected: %s and %s", locale, cat.LocaleID)
+			return nil, fmt.Errorf("unable to decode catalog file %s: %v", inputFile, err)
+		case c.Header.Kind != lxn.KindCatalog:
+			return nil, fmt.Errorf("%s is a %s, but bundle only accepts catalogs (use the link command for dictionaries)", inputFile, c.Header.Kind)
+		case first != "" && c.Header.CLDRVersion != firstCLDR:
+			return nil, fmt.Errorf("CLDR version mismatch: %s was compiled with CLDR %s, but %s with CLDR %s", first, firstCLDR, inputFile, c.Header.CLDRVersion)
+		case locale != "" && c.Catalog.LocaleID != locale:
+			return nil, fmt.Errorf("multiple locales detected: %s and %s", locale, c.Catalog.LocaleID)
 		}
 
-		locale = cat.LocaleID
-		messages = append(messages, cat.Messages...)
+		if first == "" {
+			first, firstCLDR = inputFile, c.Header.CLDRVersion
+		}
+		locale = c.Catalog.LocaleID
+		m.add(inputFile, c.Catalog.Messages)
 	}
 
+	messages, err := m.merge()
+	if err != nil {
+		return nil, err
+	}
 	return &lxn.Catalog{
 		LocaleID: locale,
 		Messages: messages,
+	}, nil
+}
+
+// readInput reads a binary input file and records it as input of the cached
+// output.
+func readInput(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		cache.addInput(filename, data)
+	}
+	return data, nil
+}
+
+// merger collects the messages of binary input files and merges them with the
+// conflict policy and the namespaces of the options.
+type merger struct {
+	policy     lxn.ConflictPolicy
+	priorities map[string]int
+	namespace  bool
+	sources    []lxn.MergeSource
+}
+
+func newMerger(opts options) (*merger, error) {
+	policy, err := lxn.ParseConflictPolicy(opts.onConflict)
+	if err != nil {
+		return nil, err
+	}
+	priorities, err := preferredFiles(opts.prefer, opts.inputFiles)
+	switch {
+	case err != nil:
+		return nil, err
+	case policy == lxn.ConflictPreferFileOrder && len(priorities) == 0:
+		return nil, errors.New("the prefer-by-file-order policy requires the preferred input files (see --prefer)")
+	case policy != lxn.ConflictPreferFileOrder && len(priorities) != 0:
+		return nil, errors.New("preferred input files require the prefer-by-file-order policy")
+	}
+
+	return &merger{
+		policy:     policy,
+		priorities: priorities,
+		namespace:  opts.namespace,
+	}, nil
+}
+
+func (m *merger) add(filename string, messages []lxn.Message) {
+	src := lxn.MergeSource{
+		Name:     filename,
+		Priority: len(m.priorities),
+		Messages: messages,
+	}
+	if prio, has := m.priorities[filepath.Clean(filename)]; has {
+		src.Priority = prio
+	}
+	if m.namespace {
+		src.Namespace = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	}
+	m.sources = append(m.sources, src)
+}
+
+// merge merges the messages of all added files and prints the overridden
+// messages.
+func (m *merger) merge() ([]lxn.Message, error) {
+	messages, overridden, err := lxn.MergeCatalogs(m.sources, m.policy)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range overridden {
+		fmt.Fprintf(os.Stderr, "overridden %s from %s by %s\n", lxn.MessageName(msg.Section, msg.Key), msg.Source, msg.By)
+	}
+	return messages, nil
+}
+
+// preferredFiles returns the priority of each preferred input file, which is
+// its index in the comma-separated list. All preferred files have to be input
+// files.
+func preferredFiles(prefer string, inputFiles []string) (map[string]int, error) {
+	if prefer == "" {
+		return nil, nil
+	}
+
+	inputs := make(map[string]struct{}, len(inputFiles))
+	for _, inputFile := range inputFiles {
+		inputs[filepath.Clean(inputFile)] = struct{}{}
+	}
+
+	priorities := make(map[string]int)
+	for _, file := range strings.Split(prefer, ",") {
+		file = filepath.Clean(strings.TrimSpace(file))
+		if _, has := inputs[file]; !has {
+			return nil, fmt.Errorf("preferred file %s is not an input file", file)
+		}
+		if _, has := priorities[file]; !has {
+			priorities[file] = len(priorities)
+		}
+	}
+	return priorities, nil
+}
+
+// cache is the build cache, or nil if no cache is used.
+var cache *buildCache
+
+func printCacheStats(opts options) {
+	if opts.cacheStats {
+		cache.printStats()
 	}
 }
 
@@ -125,6 +525,23 @@ func (w warner) Warn(msg string) {
 	fmt.Fprintln(os.Stderr, "warning:", msg)
 }
 
+// fatalErr prints the error and exits. Errors of translation files are rendered
+// with an excerpt of the source.
+func fatalErr(err error) {
+	printErr(err)
+	os.Exit(1)
+}
+
+func printErr(err error) {
+	r := lxn.ErrorRenderer{Color: isTerminal(os.Stderr) && os.Getenv("NO_COLOR") == ""}
+	r.Render(os.Stderr, err)
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
 func fatalf(msg string, args ...any) {
 	fmt.Fprintf(os.Stderr, msg, args...)
 	fmt.Fprintln(os.Stderr)
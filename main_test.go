@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/liblxn/lxnc/locale"
+)
+
+func TestLocaleSourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"und/a.lxn", "root/b.lxn", "de/c.lxn", "de/notes.txt"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		tag      string
+		expected []string
+	}{
+		{tag: "und", expected: []string{"und/a.lxn", "root/b.lxn"}},
+		{tag: "de", expected: []string{"de/c.lxn"}},
+		{tag: "fr", expected: nil},
+	}
+
+	for _, test := range tests {
+		loc, err := locale.New(test.tag)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		files, err := localeSourceFiles(dir, loc)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.tag, err)
+			continue
+		}
+
+		var expected []string
+		for _, file := range test.expected {
+			expected = append(expected, filepath.Join(dir, file))
+		}
+		if !reflect.DeepEqual(files, expected) {
+			t.Errorf("unexpected files for %s: %v", test.tag, files)
+		}
+	}
+}
+
+func TestWriteOutputKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.lxn")
+	if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := writeOutput(path, []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case string(data) != "new":
+		t.Errorf("unexpected contents: %q", data)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("unexpected file mode: %v (%v)", fi.Mode(), err)
+	}
+}
+
+func TestBuildCacheWithFallbacks(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/de/a.lxn":  "a: de\n",
+		"src/und/b.lxn": "b: root\n",
+	}
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cache = &buildCache{}
+	defer func() { cache = nil }()
+
+	opts := options{
+		command:     compileCommand,
+		locale:      "de-AT",
+		fallbackDir: filepath.Join(dir, "src"),
+		outputFile:  filepath.Join(dir, "de-AT.lxnd"),
+	}
+	rebuild := func() []byte {
+		cache.reset()
+		if _, err := build(opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(opts.outputFile)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return data
+	}
+
+	first := rebuild()
+	if cache.outputMisses != 1 {
+		t.Errorf("unexpected output misses: %d", cache.outputMisses)
+	}
+	if unchanged := rebuild(); cache.outputHits != 1 || !bytes.Equal(unchanged, first) {
+		t.Errorf("unexpected output hits: %d", cache.outputHits)
+	}
+
+	// Changing a file of a fallback locale rebuilds the output.
+	if err := os.WriteFile(filepath.Join(dir, "src", "und", "b.lxn"), []byte("b: changed\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed := rebuild(); cache.outputMisses != 1 || bytes.Equal(changed, first) {
+		t.Errorf("unexpected output misses: %d", cache.outputMisses)
+	}
+}
+
+func TestRebuildWatchesFailedIncludes(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.lxn")
+	broken := filepath.Join(dir, "common", "broken.lxn")
+	if err := os.MkdirAll(filepath.Dir(broken), 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(main, []byte("key: main\n@include \"common/broken.lxn\"\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(broken, []byte("key: ${foo\n"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cache = &buildCache{}
+	defer func() { cache = nil }()
+
+	opts := options{
+		command:    compileCommand,
+		locale:     "en",
+		outputFile: filepath.Join(dir, "en.lxnd"),
+	}
+	if _, err := rebuild(&opts, []string{main}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// The included file is watched, so fixing it triggers the next build.
+	var inputs []string
+	for _, input := range cache.inputs {
+		inputs = append(inputs, input.Name)
+	}
+	if expected := []string{main, broken}; !reflect.DeepEqual(inputs, expected) {
+		t.Errorf("unexpected inputs: %v", inputs)
+	}
+}
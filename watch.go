@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/liblxn/lxnc/internal/glob"
+)
+
+// watchInterval is the interval in which the inputs are polled for changes.
+const watchInterval = 500 * time.Millisecond
+
+// watch builds the output and rebuilds it whenever one of its inputs changes,
+// until the process is terminated. The inputs are polled, so watching works on
+// all platforms and file systems. The input arguments are expanded again before
+// each build, so new files in watched directories are picked up.
+//
+// Only the translation files which changed are parsed again, and the output is
+// only rewritten if its content changed.
+func watch(opts options, args []string) {
+	if cache == nil {
+		cache = &buildCache{} // in-memory
+	}
+
+	for {
+		start := time.Now()
+		output, err := rebuild(&opts, args)
+		stamp := start.Format("15:04:05")
+		switch {
+		case err != nil:
+			printErr(err)
+			fmt.Fprintf(os.Stderr, "[%s] build failed, waiting for changes\n", stamp)
+		case output == "":
+			fmt.Fprintf(os.Stderr, "[%s] no input files, waiting for changes\n", stamp)
+		default:
+			fmt.Fprintf(os.Stderr, "[%s] built %s in %v\n", stamp, output, time.Since(start).Round(time.Millisecond))
+		}
+		printCacheStats(opts)
+
+		files := append([]string(nil), opts.inputFiles...)
+		for _, input := range cache.inputs {
+			files = append(files, input.Name)
+		}
+		dirs := watchedDirs(args, opts.fallbackDir)
+
+		state := snapshot(files, dirs)
+		for {
+			time.Sleep(watchInterval)
+			if !maps.Equal(snapshot(files, dirs), state) {
+				break
+			}
+		}
+	}
+}
+
+// rebuild expands the input arguments again and builds the output. If the
+// inputs cannot be expanded, e.g. because a directory is removed while it is
+// walked, the error is returned and the previous input files are kept, so they
+// are still watched.
+func rebuild(opts *options, args []string) (string, error) {
+	switch opts.command {
+	case compileCommand, exportCommand:
+		files, err := expandInputs(args, splitPatterns(opts.include), splitPatterns(opts.exclude))
+		if err != nil {
+			return "", err
+		}
+		opts.inputFiles = files
+	}
+	cache.reset()
+	return build(*opts)
+}
+
+// watchedDirs returns the directories which are searched for translation files,
+// so added and removed files can be detected.
+func watchedDirs(args []string, fallbackDir string) []string {
+	var dirs []string
+	for _, arg := range args {
+		if pattern := filepath.ToSlash(arg); glob.HasMeta(pattern) {
+			dirs = append(dirs, filepath.FromSlash(glob.Base(pattern)))
+		} else if fi, err := os.Stat(arg); err == nil && fi.IsDir() {
+			dirs = append(dirs, arg)
+		}
+	}
+	if fallbackDir != "" {
+		dirs = append(dirs, fallbackDir)
+	}
+	return dirs
+}
+
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime int64
+}
+
+// snapshot returns the state of the files and of all translation files in the
+// directories.
+func snapshot(files []string, dirs []string) map[string]fileState {
+	state := make(map[string]fileState, len(files))
+	for _, filename := range files {
+		state[filename] = statFile(filename)
+	}
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(filename string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && filepath.Ext(filename) == sourceExt {
+				state[filename] = statFile(filename)
+			}
+			return nil
+		})
+	}
+	return state
+}
+
+func statFile(filename string) fileState {
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: fi.Size(), modTime: fi.ModTime().UnixNano()}
+}